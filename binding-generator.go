@@ -9,17 +9,17 @@ import (
 	"os"
 	//"os/exec"
 	"strings"
+	"time"
 )
 
 type Deps struct {
 	Pkgs []string
 	Headers []string
 	Typedefs map[string]string
-	Imports []string
 }
 
 var knownPackages map[string] Deps
-var common string
+var common, commonC string
 
 func CreatePackageRoot(pkg string) string {
 	root := filepath.Join("src/gi", pkg)
@@ -32,6 +32,102 @@ func OpenSourceFile(root, pkg string) *os.File {
 	return f
 }
 
+// the pkg-config line, includes and typedefs shared by every file in a package
+func WriteCgoHeader(f *os.File, deps Deps) {
+	f.WriteString(fmt.Sprintf("#cgo pkg-config: %s\n", strings.Join(deps.Pkgs, " ")))
+	for _, header := range deps.Headers {
+		f.WriteString(fmt.Sprintf("#include <%s>\n", header))
+	}
+	for key, value := range deps.Typedefs {
+		f.WriteString(fmt.Sprintf("#define %s %s\n", key, value))
+	}
+	f.WriteString("\n")
+}
+
+// the packages a file's code uses, each on its own line
+func WriteImports(f *os.File, code string) {
+	for _, imp := range gogi.GetImports(code) {
+		f.WriteString(fmt.Sprintf("import \"%s\"\n", imp))
+	}
+	f.WriteString("\n")
+}
+
+// what every package is generated from besides its own typelib and blacklist
+var generatorInputs = []string{"binding-generator.go", "deps.json", "misc", "src/gogi"}
+
+// Checks whether anything under path was modified after t
+func ModifiedSince(path string, t time.Time) bool {
+	modified := false
+	filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err == nil && info.ModTime().After(t) {
+			modified = true
+		}
+		return nil
+	})
+	return modified
+}
+
+// Checks whether a namespace's package was generated after everything it's
+// generated from last changed
+func UpToDate(namespace string) bool {
+	pkg := gogi.PackageName(namespace)
+	pkg_root := filepath.Join("src/gi", pkg)
+	var generated time.Time
+	for _, file := range []string{pkg + ".go", pkg + "_export.go"} {
+		stat, err := os.Stat(filepath.Join(pkg_root, file))
+		if err != nil {
+			return false
+		}
+		if generated.IsZero() || stat.ModTime().Before(generated) {
+			generated = stat.ModTime()
+		}
+	}
+
+	typelib := gogi.GetTypelibPath(namespace)
+	if typelib == "" {
+		return false
+	}
+	inputs := append([]string{typelib, filepath.Join("blacklist", namespace)}, generatorInputs...)
+	for _, input := range inputs {
+		if ModifiedSince(input, generated) {
+			return false
+		}
+	}
+	return true
+}
+
+func ReadMisc(name string) (string, bool) {
+	content, err := ioutil.ReadFile(filepath.Join("misc", name))
+	if err != nil {
+		fmt.Printf("Failed to read misc/%s\n", name)
+		return "", false
+	}
+	return string(content), true
+}
+
+// Reads the code that only one namespace's package gets, such as GObject's
+// subclass registry, from misc/<namespace>: C from .c files, exported Go from
+// _export.go files and the rest of the Go from other .go files
+func ReadNamespaceMisc(namespace string) (g string, c string, exports string) {
+	files, _ := filepath.Glob(filepath.Join("misc", namespace, "*"))
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			fmt.Printf("Failed to read %s\n", file)
+			continue
+		}
+		switch {
+			case strings.HasSuffix(file, "_export.go"):
+				exports += string(content) + "\n"
+			case strings.HasSuffix(file, ".go"):
+				g += string(content) + "\n"
+			case strings.HasSuffix(file, ".c"):
+				c += string(content) + "\n"
+		}
+	}
+	return
+}
+
 func Process(namespace string) {
 	infos := gogi.GetInfos(namespace)
 
@@ -39,6 +135,7 @@ func Process(namespace string) {
 
 	var c_code string
 	var go_code string
+	c_code += gogi.WriteCallbackSupport() + "\n"
 	for _, info := range infos {
 		if info.IsDeprecated() {
 			continue
@@ -47,12 +144,20 @@ func Process(namespace string) {
 		switch info.Type {
 			case gogi.Object:
 				g, c = gogi.WriteObject(info)
+			case gogi.Interface:
+				g, c = gogi.WriteInterface(info)
 			case gogi.Struct:
 				g, c = gogi.WriteStruct(info)
+			case gogi.Union:
+				g, c = gogi.WriteUnion(info)
 			case gogi.Enum, gogi.Flags:
 				g, c = gogi.WriteEnum(info)
 			case gogi.Function:
 				g, c = gogi.WriteFunction(info, nil)
+			case gogi.Callback:
+				g, c = gogi.WriteCallback(info)
+			case gogi.Constant:
+				g = gogi.WriteConstant(info, nil)
 			default:
 				//fmt.Printf("unknown info '%s' of type %s\n", info.GetName(), gogi.InfoTypeToString(info.Type))
 		}
@@ -80,33 +185,42 @@ func Process(namespace string) {
 		if c != "" { c_code += c + "\n" }
 	}
 
-	pkg := strings.ToLower(namespace)
+	pkg := gogi.PackageName(namespace)
 	deps, deps_exist := knownPackages[namespace]
+	if !deps_exist {
+		fmt.Printf("%s isn't in deps.json, so its package won't know which C libraries to use\n", namespace)
+	}
 	pkg_root := CreatePackageRoot(pkg)
+	misc, miscC, miscExports := ReadNamespaceMisc(namespace)
 
 	f := OpenSourceFile(pkg_root, pkg)
 	f.WriteString("package " + pkg + "\n\n")
 	f.WriteString("/*\n")
 	if deps_exist {
-		f.WriteString(fmt.Sprintf("#cgo pkg-config: %s\n", strings.Join(deps.Pkgs, " ")))
-		for _, header := range deps.Headers {
-			f.WriteString(fmt.Sprintf("#include <%s>\n", header))
-		}
-		for key, value := range deps.Typedefs {
-			f.WriteString(fmt.Sprintf("#define %s %s\n", key, value))
-		}
-		f.WriteString("\n")
+		WriteCgoHeader(f, deps)
 		f.WriteString("GList *EMPTY_GLIST = NULL;\n")
 	}
+	f.WriteString(commonC + "\n")
+	f.WriteString(miscC)
 	f.WriteString(c_code + "\n")
 	f.WriteString("*/\nimport \"C\"\n")
-	for _, imp := range deps.Imports {
-		f.WriteString(fmt.Sprintf("import \"%s\"\n", imp))
-	}
-	// TODO: find a way to keep track of additional imports
-	//f.WriteString("import \"unsafe\"\n\n")
+	WriteImports(f, go_code + common + misc)
 	f.WriteString(go_code)
 	f.WriteString("\n" + common)
+	f.WriteString("\n" + misc)
+	f.Close()
+
+	// exported Go functions can't share a file with C definitions
+	f = OpenSourceFile(pkg_root, pkg + "_export")
+	f.WriteString("package " + pkg + "\n\n")
+	f.WriteString("/*\n")
+	if deps_exist {
+		WriteCgoHeader(f, deps)
+	}
+	f.WriteString("*/\nimport \"C\"\n")
+	exports := gogi.GetExports() + miscExports
+	WriteImports(f, exports)
+	f.WriteString(exports)
 	f.Close()
 
 	// now build it
@@ -123,6 +237,7 @@ func Process(namespace string) {
 func main() {
 	if len(os.Args) < 2 {
 		fmt.Println("usage: go run binding-generator.go <namespace>")
+		fmt.Println("generates the namespace and everything it depends on, skipping packages that are up to date")
 		return
 	}
 
@@ -140,31 +255,35 @@ func main() {
 	namespace := os.Args[1]
 	gogi.Init()
 
+	// loading it loads everything it depends on too
 	loaded := gogi.LoadNamespace(namespace)
 	if !loaded {
 		fmt.Printf("Failed to load namespace '%s'\n", namespace)
 		return
 	}
 
-	var commonBytes []byte
-	commonBytes, err = ioutil.ReadFile("misc/common.go")
-	if err != nil {
-		fmt.Println("Failed to read misc/common.go")
+	var ok bool
+	if common, ok = ReadMisc("common.go"); !ok {
+		return
+	}
+	if commonC, ok = ReadMisc("common.c"); !ok {
 		return
-	} else {
-		common = string(commonBytes)
 	}
 
-	/*
-	dependencies := gogi.GetDependencies(namespace)
-	for _, dep := range dependencies {
-		nameAndVersion := strings.Split(dep, "-")
-		name := nameAndVersion[0]
-		//version := nameAndVersion[1]
+	// dependencies come first, since their packages are imported by the ones after them
+	generated := make(map[string]bool)
+	for _, name := range gogi.GetDependencyOrder(namespace) {
+		stale := false
+		for _, dep := range gogi.GetDependencies(name) {
+			stale = stale || generated[dep]
+		}
+		if !stale && UpToDate(name) {
+			fmt.Printf("%s is up to date\n", name)
+			continue
+		}
+		gogi.LoadNamespace(name)
 		Process(name)
+		generated[name] = true
 	}
-	*/
-
-	Process(namespace)
 	fmt.Println("done.")
 }
@@ -0,0 +1,5 @@
+/* Converting between GVariants and Go values */
+
+GVariant **gogi_variants_new(gsize n) { return g_new0(GVariant *, n); }
+void gogi_variants_set(GVariant **variants, gsize i, GVariant *variant) { variants[i] = variant; }
+GVariant *gogi_variant_child(GVariant **variants, gsize i) { return variants[i]; }
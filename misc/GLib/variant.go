@@ -0,0 +1,694 @@
+/* GVariants and the Go values they hold */
+
+// A GVariant. It holds a reference, which is released once it's collected.
+type Variant struct {
+	ptr *C.GVariant
+}
+
+var variantType = reflect.TypeOf((*Variant)(nil))
+
+func wrapVariant(ptr *C.GVariant, owned bool) *Variant {
+	if ptr == nil {
+		return nil
+	}
+	if !owned || C.g_variant_is_floating((C.gpointer)(ptr)) != 0 {
+		C.g_variant_ref_sink(ptr)
+	}
+	self := &Variant{ptr}
+	runtime.SetFinalizer(self, func(self *Variant) {
+		C.g_variant_unref(self.ptr)
+	})
+	return self
+}
+
+// Wraps a GVariant from C, taking a reference unless it was handed one
+func WrapVariant(ptr unsafe.Pointer, owned bool) *Variant {
+	return wrapVariant((*C.GVariant)(ptr), owned)
+}
+
+func (self *Variant) Native() unsafe.Pointer {
+	return unsafe.Pointer(self.ptr)
+}
+
+// Gets the type string of the GVariant, like "a{sv}"
+func (self *Variant) TypeString() string {
+	return variantTypeString(self.ptr)
+}
+
+// Gets the GVariant in its text format, with type annotations
+func (self *Variant) String() string {
+	c_str := C.g_variant_print(self.ptr, 1)
+	defer C.g_free((C.gpointer)(c_str))
+	return C.GoString((*C.char)(c_str))
+}
+
+// Creates a GVariant of the type a type string gives from a Go value. Basic
+// types come from Go values of any matching kind, arrays from slices, and
+// dictionaries from maps, or from structs with a field for each entry; those
+// go by their variant tag, or else by their name. Tuples come from structs,
+// field by field in order, or from []interface{}. Maybe types are nothing for
+// nil and otherwise come from the value, or what it points to. Variants come
+// from any Go value, as VariantTypeOf types it, or from another *Variant.
+func NewVariant(typeString string, v interface{}) (*Variant, error) {
+	c_type := (*C.gchar)(C.CString(typeString))
+	defer C.g_free((C.gpointer)(c_type))
+	if C.g_variant_type_string_is_valid(c_type) == 0 {
+		return nil, fmt.Errorf("%q isn't a GVariant type", typeString)
+	}
+	ptr, err := variantFromGo(typeString, reflect.ValueOf(v))
+	if err != nil {
+		return nil, err
+	}
+	return wrapVariant(ptr, false), nil
+}
+
+// Gets a GVariant for a Go value handed to a function that takes one, with
+// the type VariantTypeOf gives it; a *Variant is used as it is. It panics if
+// the value can't be held by one.
+func ToVariant(v interface{}) *Variant {
+	if variant, ok := v.(*Variant); ok {
+		return variant
+	}
+	typeString, err := VariantTypeOf(v)
+	var variant *Variant
+	if err == nil {
+		variant, err = NewVariant(typeString, v)
+	}
+	if err != nil {
+		panic("ToVariant: " + err.Error())
+	}
+	return variant
+}
+
+// Gets the type string of the GVariant that suits a Go value: numbers get
+// the type of their size, with int and uint taken as 64 bits, slices are
+// arrays, maps are dictionaries, structs are tuples, pointers are maybe types
+// and interfaces and *Variants are variants
+func VariantTypeOf(v interface{}) (string, error) {
+	if v == nil {
+		return "", fmt.Errorf("nil has no GVariant type")
+	}
+	return variantTypeOf(reflect.TypeOf(v))
+}
+
+func variantTypeOf(t reflect.Type) (string, error) {
+	if t == variantType {
+		return "v", nil
+	}
+	switch t.Kind() {
+		case reflect.Bool:
+			return "b", nil
+		case reflect.Uint8:
+			return "y", nil
+		case reflect.Int16:
+			return "n", nil
+		case reflect.Uint16:
+			return "q", nil
+		case reflect.Int32:
+			return "i", nil
+		case reflect.Uint32:
+			return "u", nil
+		case reflect.Int, reflect.Int64:
+			return "x", nil
+		case reflect.Uint, reflect.Uint64:
+			return "t", nil
+		case reflect.Float32, reflect.Float64:
+			return "d", nil
+		case reflect.String:
+			return "s", nil
+		case reflect.Interface:
+			return "v", nil
+		case reflect.Slice, reflect.Array:
+			elem, err := variantTypeOf(t.Elem())
+			return "a" + elem, err
+		case reflect.Map:
+			key, err := variantTypeOf(t.Key())
+			if err != nil {
+				return "", err
+			}
+			value, err := variantTypeOf(t.Elem())
+			return "a{" + key + value + "}", err
+		case reflect.Ptr:
+			elem, err := variantTypeOf(t.Elem())
+			return "m" + elem, err
+		case reflect.Struct:
+			typeString := "("
+			for _, field := range variantFields(t) {
+				elem, err := variantTypeOf(t.Field(field.index).Type)
+				if err != nil {
+					return "", err
+				}
+				typeString += elem
+			}
+			return typeString + ")", nil
+	}
+	return "", fmt.Errorf("no GVariant type suits %s", t)
+}
+
+// Gets the Go value the GVariant holds, going by its type string: basic types
+// come out as the Go type of their size, with handles as int32, arrays as
+// slices and dictionaries as maps of those, tuples as []interface{}, and
+// variants and maybe types as the value inside them, or nil for nothing
+func (self *Variant) Go() interface{} {
+	if self == nil {
+		return nil
+	}
+	return variantToGo(self.ptr)
+}
+
+// Copies the GVariant into what a pointer points to, converting it to the Go
+// types there the same ways NewVariant converts from them
+func (self *Variant) Store(ptr interface{}) error {
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("can't store a GVariant in a %T", ptr)
+	}
+	return storeVariant(self.ptr, rv.Elem())
+}
+
+// A struct field that a GVariant holds, by its index and the key it has in dictionaries
+type variantField struct {
+	index int
+	name string
+}
+
+// Gets the exported fields of a struct, leaving out ones tagged variant:"-"
+func variantFields(t reflect.Type) (fields []variantField) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("variant")
+		if field.PkgPath != "" || tag == "-" {
+			continue
+		}
+		if tag == "" {
+			tag = field.Name
+		}
+		fields = append(fields, variantField{i, tag})
+	}
+	return
+}
+
+// Splits the first complete type off the front of a type string
+func splitVariantType(typeString string) (string, string) {
+	depth := 0
+	for i := 0; i < len(typeString); i++ {
+		switch typeString[i] {
+			case 'a', 'm':
+				// they're followed by the type they contain
+				continue
+			case '(', '{':
+				depth++
+			case ')', '}':
+				depth--
+		}
+		if depth == 0 {
+			return typeString[:i + 1], typeString[i + 1:]
+		}
+	}
+	return typeString, ""
+}
+
+// Splits the type string of a tuple or dictionary entry into the types it contains
+func variantMemberTypes(typeString string) (types []string) {
+	rest := typeString[1:len(typeString) - 1]
+	for rest != "" {
+		var member string
+		member, rest = splitVariantType(rest)
+		types = append(types, member)
+	}
+	return
+}
+
+func variantTypeString(ptr *C.GVariant) string {
+	return C.GoString((*C.char)(C.g_variant_get_type_string(ptr)))
+}
+
+func newVariantType(typeString string) *C.GVariantType {
+	c_type := (*C.gchar)(C.CString(typeString))
+	defer C.g_free((C.gpointer)(c_type))
+	return C.g_variant_type_new(c_type)
+}
+
+// Gets the number a Go value holds, as each of the kinds of number
+func goNumber(rv reflect.Value) (i int64, u uint64, f float64, ok bool) {
+	switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i = rv.Int()
+			return i, (uint64)(i), (float64)(i), true
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			u = rv.Uint()
+			return (int64)(u), u, (float64)(u), true
+		case reflect.Float32, reflect.Float64:
+			f = rv.Float()
+			return (int64)(f), (uint64)(f), f, true
+	}
+	return 0, 0, 0, false
+}
+
+// Builds a new floating GVariant of the given type from a Go value, or gets a
+// *Variant's own, without a reference. Either way, g_variant_ref_sink gets a
+// reference to keep, and containers built from it take one of their own.
+func variantFromGo(typeString string, rv reflect.Value) (*C.GVariant, error) {
+	// interfaces are converted by what they hold
+	for rv.IsValid() && rv.Kind() == reflect.Interface && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.IsValid() && rv.Type() == variantType && !rv.IsNil() {
+		variant := rv.Interface().(*Variant)
+		if typeString == "v" {
+			return C.g_variant_new_variant(variant.ptr), nil
+		}
+		if variant.TypeString() == typeString {
+			return variant.ptr, nil
+		}
+		return nil, fmt.Errorf("can't make a %s GVariant from a %s one", typeString, variant.TypeString())
+	}
+	fail := func() (*C.GVariant, error) {
+		if !rv.IsValid() {
+			return nil, fmt.Errorf("can't make a %s GVariant from nil", typeString)
+		}
+		return nil, fmt.Errorf("can't make a %s GVariant from a %s", typeString, rv.Type())
+	}
+	if !rv.IsValid() && typeString[0] != 'm' {
+		return fail()
+	}
+
+	switch typeString[0] {
+		case 'b':
+			if rv.Kind() != reflect.Bool {
+				return fail()
+			}
+			if rv.Bool() {
+				return C.g_variant_new_boolean(1), nil
+			}
+			return C.g_variant_new_boolean(0), nil
+		case 'y', 'n', 'q', 'i', 'u', 'x', 't', 'h', 'd':
+			i, u, f, ok := goNumber(rv)
+			if !ok {
+				return fail()
+			}
+			switch typeString[0] {
+				case 'y':
+					return C.g_variant_new_byte((C.guchar)(u)), nil
+				case 'n':
+					return C.g_variant_new_int16((C.gint16)(i)), nil
+				case 'q':
+					return C.g_variant_new_uint16((C.guint16)(u)), nil
+				case 'i':
+					return C.g_variant_new_int32((C.gint32)(i)), nil
+				case 'u':
+					return C.g_variant_new_uint32((C.guint32)(u)), nil
+				case 'x':
+					return C.g_variant_new_int64((C.gint64)(i)), nil
+				case 't':
+					return C.g_variant_new_uint64((C.guint64)(u)), nil
+				case 'h':
+					return C.g_variant_new_handle((C.gint32)(i)), nil
+			}
+			return C.g_variant_new_double((C.gdouble)(f)), nil
+		case 's', 'o', 'g':
+			if rv.Kind() != reflect.String {
+				return fail()
+			}
+			c_str := (*C.gchar)(C.CString(rv.String()))
+			defer C.g_free((C.gpointer)(c_str))
+			switch typeString[0] {
+				case 'o':
+					if C.g_variant_is_object_path(c_str) == 0 {
+						return nil, fmt.Errorf("%q isn't an object path", rv.String())
+					}
+					return C.g_variant_new_object_path(c_str), nil
+				case 'g':
+					if C.g_variant_is_signature(c_str) == 0 {
+						return nil, fmt.Errorf("%q isn't a signature", rv.String())
+					}
+					return C.g_variant_new_signature(c_str), nil
+			}
+			return C.g_variant_new_string(c_str), nil
+		case 'v':
+			elemType, err := variantTypeOf(rv.Type())
+			if err != nil {
+				return nil, err
+			}
+			child, err := variantFromGo(elemType, rv)
+			if err != nil {
+				return nil, err
+			}
+			return C.g_variant_new_variant(child), nil
+		case 'm':
+			elemType := typeString[1:]
+			nothing := !rv.IsValid()
+			switch {
+				case nothing:
+				case rv.Kind() == reflect.Ptr, rv.Kind() == reflect.Interface, rv.Kind() == reflect.Map, rv.Kind() == reflect.Slice:
+					nothing = rv.IsNil()
+			}
+			if nothing {
+				c_type := newVariantType(elemType)
+				defer C.g_variant_type_free(c_type)
+				return C.g_variant_new_maybe(c_type, nil), nil
+			}
+			if rv.Kind() == reflect.Ptr && elemType[0] != 'm' {
+				rv = rv.Elem()
+			}
+			child, err := variantFromGo(elemType, rv)
+			if err != nil {
+				return nil, err
+			}
+			return C.g_variant_new_maybe(nil, child), nil
+		case 'a':
+			return variantArrayFromGo(typeString, rv)
+		case '(':
+			types := variantMemberTypes(typeString)
+			var values []reflect.Value
+			switch rv.Kind() {
+				case reflect.Struct:
+					for _, field := range variantFields(rv.Type()) {
+						values = append(values, rv.Field(field.index))
+					}
+				case reflect.Slice, reflect.Array:
+					for i := 0; i < rv.Len(); i++ {
+						values = append(values, rv.Index(i))
+					}
+				default:
+					return fail()
+			}
+			if len(values) != len(types) {
+				return nil, fmt.Errorf("can't make a %s GVariant from %d values", typeString, len(values))
+			}
+			children, err := variantChildren(types, values)
+			if err != nil {
+				return nil, err
+			}
+			defer C.g_free((C.gpointer)(children))
+			return C.g_variant_new_tuple(children, (C.gsize)(len(values))), nil
+	}
+	return fail()
+}
+
+// Builds an array, or a dictionary, from a slice, map or struct
+func variantArrayFromGo(typeString string, rv reflect.Value) (*C.GVariant, error) {
+	elemType := typeString[1:]
+	var types []string
+	var values []reflect.Value
+	if elemType[0] == '{' {
+		memberTypes := variantMemberTypes(elemType)
+		switch rv.Kind() {
+			case reflect.Map:
+				for _, key := range rv.MapKeys() {
+					types = append(types, memberTypes...)
+					values = append(values, key, rv.MapIndex(key))
+				}
+			case reflect.Struct:
+				if memberTypes[0] != "s" {
+					return nil, fmt.Errorf("can't make a %s GVariant from a struct", typeString)
+				}
+				for _, field := range variantFields(rv.Type()) {
+					types = append(types, memberTypes...)
+					values = append(values, reflect.ValueOf(field.name), rv.Field(field.index))
+				}
+			default:
+				return nil, fmt.Errorf("can't make a %s GVariant from a %s", typeString, rv.Type())
+		}
+	} else {
+		switch rv.Kind() {
+			case reflect.Slice, reflect.Array:
+				for i := 0; i < rv.Len(); i++ {
+					types = append(types, elemType)
+					values = append(values, rv.Index(i))
+				}
+			default:
+				return nil, fmt.Errorf("can't make a %s GVariant from a %s", typeString, rv.Type())
+		}
+	}
+
+	children, err := variantChildren(types, values)
+	if err != nil {
+		return nil, err
+	}
+	defer C.g_free((C.gpointer)(children))
+	n := len(values)
+	if elemType[0] == '{' {
+		// the keys and values are paired up into entries
+		n /= 2
+		for i := 0; i < n; i++ {
+			entry := C.g_variant_new_dict_entry(C.gogi_variant_child(children, (C.gsize)(2 * i)), C.gogi_variant_child(children, (C.gsize)(2 * i + 1)))
+			C.gogi_variants_set(children, (C.gsize)(i), entry)
+		}
+	}
+	c_type := newVariantType(elemType)
+	defer C.g_variant_type_free(c_type)
+	return C.g_variant_new_array(c_type, children, (C.gsize)(n)), nil
+}
+
+// Builds a GVariant of each type from each Go value, in a C array that needs
+// freeing; if any of them fails, the ones before it are released, which sinking
+// each one first does for borrowed ones as well as floating ones
+func variantChildren(types []string, values []reflect.Value) (**C.GVariant, error) {
+	children := C.gogi_variants_new((C.gsize)(len(values)))
+	for i, value := range values {
+		child, err := variantFromGo(types[i], value)
+		if err != nil {
+			for j := 0; j < i; j++ {
+				C.g_variant_unref(C.g_variant_ref_sink(C.gogi_variant_child(children, (C.gsize)(j))))
+			}
+			C.g_free((C.gpointer)(children))
+			return nil, err
+		}
+		C.gogi_variants_set(children, (C.gsize)(i), child)
+	}
+	return children, nil
+}
+
+// Gets the Go type that a GVariant type comes out of Go() as
+func variantGoType(typeString string) reflect.Type {
+	switch typeString[0] {
+		case 'b':
+			return reflect.TypeOf(false)
+		case 'y':
+			return reflect.TypeOf(uint8(0))
+		case 'n':
+			return reflect.TypeOf(int16(0))
+		case 'q':
+			return reflect.TypeOf(uint16(0))
+		case 'i', 'h':
+			return reflect.TypeOf(int32(0))
+		case 'u':
+			return reflect.TypeOf(uint32(0))
+		case 'x':
+			return reflect.TypeOf(int64(0))
+		case 't':
+			return reflect.TypeOf(uint64(0))
+		case 'd':
+			return reflect.TypeOf(float64(0))
+		case 's', 'o', 'g':
+			return reflect.TypeOf("")
+		case 'a':
+			if typeString[1] == '{' {
+				memberTypes := variantMemberTypes(typeString[1:])
+				return reflect.MapOf(variantGoType(memberTypes[0]), variantGoType(memberTypes[1]))
+			}
+			return reflect.SliceOf(variantGoType(typeString[1:]))
+		case '(':
+			return reflect.TypeOf([]interface{}{})
+	}
+	// variants and maybe types hold anything, or nothing
+	return reflect.TypeOf((*interface{})(nil)).Elem()
+}
+
+func variantToGo(ptr *C.GVariant) interface{} {
+	typeString := variantTypeString(ptr)
+	var child *C.GVariant
+	switch typeString[0] {
+		case 'v':
+			child = C.g_variant_get_variant(ptr)
+		case 'm':
+			child = C.g_variant_get_maybe(ptr)
+			if child == nil {
+				return nil
+			}
+		default:
+			rv := reflect.New(variantGoType(typeString)).Elem()
+			storeVariant(ptr, rv)
+			return rv.Interface()
+	}
+	defer C.g_variant_unref(child)
+	return variantToGo(child)
+}
+
+// Stores a GVariant in a Go value, converting it to the value's type
+func storeVariant(ptr *C.GVariant, rv reflect.Value) error {
+	typeString := variantTypeString(ptr)
+	fail := func() error {
+		return fmt.Errorf("can't store a %s GVariant in a %s", typeString, rv.Type())
+	}
+	if rv.Type() == variantType {
+		rv.Set(reflect.ValueOf(wrapVariant(ptr, false)))
+		return nil
+	}
+	switch rv.Kind() {
+		case reflect.Interface:
+			natural := variantToGo(ptr)
+			if natural == nil {
+				rv.Set(reflect.Zero(rv.Type()))
+				return nil
+			}
+			if !reflect.TypeOf(natural).AssignableTo(rv.Type()) {
+				return fail()
+			}
+			rv.Set(reflect.ValueOf(natural))
+			return nil
+		case reflect.Ptr:
+			// maybe types are nil for nothing; anything else just goes where it points
+			if typeString[0] == 'm' {
+				child := C.g_variant_get_maybe(ptr)
+				if child == nil {
+					rv.Set(reflect.Zero(rv.Type()))
+					return nil
+				}
+				defer C.g_variant_unref(child)
+				ptr = child
+			}
+			if rv.IsNil() {
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			return storeVariant(ptr, rv.Elem())
+	}
+
+	switch typeString[0] {
+		case 'b':
+			if rv.Kind() != reflect.Bool {
+				return fail()
+			}
+			rv.SetBool(C.g_variant_get_boolean(ptr) != 0)
+			return nil
+		case 'y', 'n', 'q', 'i', 'u', 'x', 't', 'h', 'd':
+			var number reflect.Value
+			switch typeString[0] {
+				case 'y':
+					number = reflect.ValueOf((uint8)(C.g_variant_get_byte(ptr)))
+				case 'n':
+					number = reflect.ValueOf((int16)(C.g_variant_get_int16(ptr)))
+				case 'q':
+					number = reflect.ValueOf((uint16)(C.g_variant_get_uint16(ptr)))
+				case 'i':
+					number = reflect.ValueOf((int32)(C.g_variant_get_int32(ptr)))
+				case 'u':
+					number = reflect.ValueOf((uint32)(C.g_variant_get_uint32(ptr)))
+				case 'x':
+					number = reflect.ValueOf((int64)(C.g_variant_get_int64(ptr)))
+				case 't':
+					number = reflect.ValueOf((uint64)(C.g_variant_get_uint64(ptr)))
+				case 'h':
+					number = reflect.ValueOf((int32)(C.g_variant_get_handle(ptr)))
+				case 'd':
+					number = reflect.ValueOf((float64)(C.g_variant_get_double(ptr)))
+			}
+			i, u, f, _ := goNumber(number)
+			switch rv.Kind() {
+				case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+					rv.SetInt(i)
+				case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+					rv.SetUint(u)
+				case reflect.Float32, reflect.Float64:
+					rv.SetFloat(f)
+				default:
+					return fail()
+			}
+			return nil
+		case 's', 'o', 'g':
+			if rv.Kind() != reflect.String {
+				return fail()
+			}
+			rv.SetString(C.GoString((*C.char)(C.g_variant_get_string(ptr, nil))))
+			return nil
+		case 'v':
+			child := C.g_variant_get_variant(ptr)
+			defer C.g_variant_unref(child)
+			return storeVariant(child, rv)
+		case 'm':
+			child := C.g_variant_get_maybe(ptr)
+			if child == nil {
+				rv.Set(reflect.Zero(rv.Type()))
+				return nil
+			}
+			defer C.g_variant_unref(child)
+			return storeVariant(child, rv)
+	}
+
+	n := (int)(C.g_variant_n_children(ptr))
+	switch {
+		case typeString[0] == 'a' && typeString[1] == '{' && rv.Kind() == reflect.Map:
+			rv.Set(reflect.MakeMap(rv.Type()))
+			for i := 0; i < n; i++ {
+				entry := C.g_variant_get_child_value(ptr, (C.gsize)(i))
+				key := reflect.New(rv.Type().Key()).Elem()
+				value := reflect.New(rv.Type().Elem()).Elem()
+				err := storeVariantChild(entry, 0, key)
+				if err == nil {
+					err = storeVariantChild(entry, 1, value)
+				}
+				C.g_variant_unref(entry)
+				if err != nil {
+					return err
+				}
+				rv.SetMapIndex(key, value)
+			}
+			return nil
+		case typeString[0] == 'a' && typeString[1] == '{' && rv.Kind() == reflect.Struct:
+			// entries without a field are left out
+			fields := make(map[string]int)
+			for _, field := range variantFields(rv.Type()) {
+				fields[field.name] = field.index
+			}
+			for i := 0; i < n; i++ {
+				entry := C.g_variant_get_child_value(ptr, (C.gsize)(i))
+				key := reflect.New(reflect.TypeOf("")).Elem()
+				err := storeVariantChild(entry, 0, key)
+				if index, ok := fields[key.String()]; ok && err == nil {
+					err = storeVariantChild(entry, 1, rv.Field(index))
+				}
+				C.g_variant_unref(entry)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		case rv.Kind() == reflect.Struct && typeString[0] == '(':
+			fields := variantFields(rv.Type())
+			if len(fields) != n {
+				return fail()
+			}
+			for i, field := range fields {
+				if err := storeVariantChild(ptr, i, rv.Field(field.index)); err != nil {
+					return err
+				}
+			}
+			return nil
+		case rv.Kind() == reflect.Slice && (typeString[0] == 'a' || typeString[0] == '('):
+			rv.Set(reflect.MakeSlice(rv.Type(), n, n))
+			for i := 0; i < n; i++ {
+				if err := storeVariantChild(ptr, i, rv.Index(i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		case rv.Kind() == reflect.Array && (typeString[0] == 'a' || typeString[0] == '('):
+			if rv.Len() != n {
+				return fail()
+			}
+			for i := 0; i < n; i++ {
+				if err := storeVariantChild(ptr, i, rv.Index(i)); err != nil {
+					return err
+				}
+			}
+			return nil
+	}
+	return fail()
+}
+
+func storeVariantChild(ptr *C.GVariant, i int, rv reflect.Value) error {
+	child := C.g_variant_get_child_value(ptr, (C.gsize)(i))
+	defer C.g_variant_unref(child)
+	return storeVariant(child, rv)
+}
@@ -0,0 +1,37 @@
+/* Registering Go types as subclasses */
+
+extern void gogi_subclass_class_init(gpointer klass, gpointer data);
+extern void gogi_subclass_instance_init(GTypeInstance *instance, gpointer klass);
+extern void gogi_subclass_forget(gpointer instance);
+
+GType gogi_register_subclass(GType parent, const gchar *name, guint class_size, guint instance_size) {
+	GTypeInfo info = {0};
+	info.class_size = class_size;
+	info.class_init = (GClassInitFunc)gogi_subclass_class_init;
+	info.instance_size = instance_size;
+	info.instance_init = (GInstanceInitFunc)gogi_subclass_instance_init;
+	return g_type_register_static(parent, name, &info, 0);
+}
+
+GType gogi_class_type(gpointer klass) { return G_TYPE_FROM_CLASS(klass); }
+GType gogi_instance_type(gpointer instance) { return G_TYPE_FROM_INSTANCE(instance); }
+
+gpointer gogi_subclass_new(GType type) {
+	return g_object_new(type, NULL);
+}
+
+// its Go value is forgotten once it's finalized
+void gogi_subclass_watch(gpointer instance) {
+	g_object_set_qdata_full(instance, g_quark_from_static_string("gogi-subclass"), instance, gogi_subclass_forget);
+}
+
+extern void gogi_subclass_interface_init(gpointer vtable, gpointer data);
+
+void gogi_add_interface(GType type, GType iface, gpointer data) {
+	GInterfaceInfo info = {0};
+	info.interface_init = (GInterfaceInitFunc)gogi_subclass_interface_init;
+	info.interface_data = data;
+	g_type_add_interface_static(type, iface, &info);
+}
+
+GType gogi_vtable_instance_type(gpointer vtable) { return ((GTypeInterface *)vtable)->g_instance_type; }
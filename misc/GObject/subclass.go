@@ -0,0 +1,174 @@
+/* Go types registered as subclasses, and the Go values of their instances */
+
+// What a Go subclass picks up from the implementation it embeds
+type subclass interface {
+	Object
+	GType() int
+	SetNative(ptr unsafe.Pointer)
+	InstallOverrides(class unsafe.Pointer, override interface{})
+	borrow()
+}
+
+var subclassMutex sync.Mutex
+var subclassTypes = make(map[C.GType]reflect.Type)
+var subclassInstances = make(map[C.gpointer]subclass)
+
+func (ob *ObjectImpl) borrow() {
+	ob.borrowed = true
+}
+
+// Registers a Go type as a new GType with the given name and returns it, or 0
+// if GLib refuses. The Go type is a struct that embeds the implementation of
+// the type it derives from, such as gtk.WidgetImpl, and proto is a pointer to
+// one. Its Do<VFunc> methods override that type's vfuncs and its ancestors',
+// and can chain up by calling Parent<VFunc>. A Go type that embeds another Go
+// type derives from the same type and inherits its overrides, so one that
+// overrides them again chains up by calling the embedded type's Do<VFunc>.
+func RegisterSubclass(name string, proto Object) int {
+	t := reflect.TypeOf(proto)
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		panic("RegisterSubclass: " + t.String() + " isn't a pointer to a struct")
+	}
+	sub, ok := proto.(subclass)
+	if !ok {
+		panic("RegisterSubclass: " + t.String() + " can't be subclassed")
+	}
+
+	var query C.GTypeQuery
+	C.g_type_query((C.GType)(sub.GType()), &query)
+	if query._type == 0 {
+		return 0
+	}
+	c_name := (*C.gchar)(C.CString(name))
+	defer C.g_free((C.gpointer)(c_name))
+
+	subclassMutex.Lock()
+	defer subclassMutex.Unlock()
+	gtype := C.gogi_register_subclass(query._type, c_name, query.class_size, query.instance_size)
+	if gtype != 0 {
+		subclassTypes[gtype] = t.Elem()
+	}
+	return (int)(gtype)
+}
+
+// Creates an instance of a type from RegisterSubclass and returns its Go
+// value. The caller holds the only reference to it, which isn't floating even
+// if the type is initially unowned, and which Unref releases.
+func NewSubclass(gtype int) Object {
+	ptr := C.gogi_subclass_new((C.GType)(gtype))
+	if C.g_object_is_floating(ptr) != 0 {
+		C.g_object_ref_sink(ptr)
+	}
+	return subclassInstance(ptr)
+}
+
+// Gets the Go value of an instance of a type from RegisterSubclass. It lasts
+// as long as the instance does, so its Unref only releases a reference.
+func SubclassInstance(ptr unsafe.Pointer) Object {
+	if ptr == nil {
+		return nil
+	}
+	return subclassInstance((C.gpointer)(ptr))
+}
+
+// Gets the class struct that the Go types in an instance's hierarchy inherit
+// their vfuncs from, for chaining up to
+func SubclassParentClass(ptr unsafe.Pointer) unsafe.Pointer {
+	subclassMutex.Lock()
+	defer subclassMutex.Unlock()
+	gtype := C.gogi_instance_type((C.gpointer)(ptr))
+	for subclassTypes[gtype] != nil {
+		gtype = C.g_type_parent(gtype)
+	}
+	return unsafe.Pointer(C.g_type_class_peek(gtype))
+}
+
+// The implementation of an interface type, such as gio.ListModelImpl, which
+// AddInterface gets the interface and its vtable layout from
+type Implementable interface {
+	GType() int
+	InstallOverrides(vtable unsafe.Pointer, override interface{})
+}
+
+// Makes a type from RegisterSubclass implement an interface, before any
+// instances of it are created. The Go type's Do<VFunc> methods fill in the
+// interface's vtable; iface is the interface's implementation, such as
+// new(gio.ListModelImpl). A Go type that embeds that implementation as well as
+// the one it derives from is the interface itself, so its values can be handed
+// to generated functions that take one as they are.
+func AddInterface(gtype int, iface Implementable) {
+	C.gogi_add_interface((C.GType)(gtype), (C.GType)(iface.GType()), callbackAdd(iface, false))
+}
+
+// Puts the overrides of a type from RegisterSubclass into its class struct
+func subclassClassInit(klass C.gpointer) {
+	subclassMutex.Lock()
+	t := subclassTypes[C.gogi_class_type(klass)]
+	subclassMutex.Unlock()
+	proto := reflect.New(t).Interface().(subclass)
+	proto.InstallOverrides(unsafe.Pointer(klass), proto)
+}
+
+// Gets an instance's Go value, creating it the first time, or nil if it isn't
+// an instance of a type from RegisterSubclass. It's usually created
+// when the instance is initialized, but its parent types can call its vfuncs
+// before then.
+func subclassInstance(instance C.gpointer) subclass {
+	subclassMutex.Lock()
+	defer subclassMutex.Unlock()
+	if ob, ok := subclassInstances[instance]; ok {
+		return ob
+	}
+	gtype := C.gogi_instance_type(instance)
+	for gtype != 0 && subclassTypes[gtype] == nil {
+		gtype = C.g_type_parent(gtype)
+	}
+	if gtype == 0 {
+		return nil
+	}
+	ob := reflect.New(subclassTypes[gtype]).Interface().(subclass)
+	subclassSetNative(ob, unsafe.Pointer(instance))
+	ob.borrow()
+	subclassInstances[instance] = ob
+	C.gogi_subclass_watch(instance)
+	return ob
+}
+
+func subclassForget(instance C.gpointer) {
+	subclassMutex.Lock()
+	defer subclassMutex.Unlock()
+	if ob, ok := subclassInstances[instance]; ok {
+		subclassSetNative(ob, nil)
+		delete(subclassInstances, instance)
+	}
+}
+
+// Points a Go value at its instance, along with the implementations it embeds
+// besides the one it derives from, such as those of interfaces it implements,
+// and those of the Go types it embeds
+func subclassSetNative(ob subclass, ptr unsafe.Pointer) {
+	ob.SetNative(ptr)
+	setEmbeddedNative(reflect.ValueOf(ob).Elem(), ptr)
+}
+
+func setEmbeddedNative(v reflect.Value, ptr unsafe.Pointer) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.Anonymous || field.PkgPath != "" || field.Type.Kind() != reflect.Struct {
+			continue
+		}
+		if impl, ok := v.Field(i).Addr().Interface().(interface{ SetNative(ptr unsafe.Pointer) }); ok {
+			impl.SetNative(ptr)
+		}
+		setEmbeddedNative(v.Field(i), ptr)
+	}
+}
+
+// Fills in the vtable of an interface added with AddInterface
+func subclassInterfaceInit(vtable C.gpointer, data C.gpointer) {
+	iface := callbackGet(data).(Implementable)
+	subclassMutex.Lock()
+	t := subclassTypes[C.gogi_vtable_instance_type(vtable)]
+	subclassMutex.Unlock()
+	iface.InstallOverrides(unsafe.Pointer(vtable), reflect.New(t).Interface())
+}
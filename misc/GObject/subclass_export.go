@@ -0,0 +1,21 @@
+/* Exported Go code for registering Go types as subclasses */
+
+//export gogi_subclass_class_init
+func gogi_subclass_class_init(klass C.gpointer, data C.gpointer) {
+	subclassClassInit(klass)
+}
+
+//export gogi_subclass_instance_init
+func gogi_subclass_instance_init(instance *C.GTypeInstance, klass C.gpointer) {
+	subclassInstance((C.gpointer)(instance))
+}
+
+//export gogi_subclass_forget
+func gogi_subclass_forget(instance C.gpointer) {
+	subclassForget(instance)
+}
+
+//export gogi_subclass_interface_init
+func gogi_subclass_interface_init(vtable C.gpointer, data C.gpointer) {
+	subclassInterfaceInit(vtable, data)
+}
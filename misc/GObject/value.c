@@ -0,0 +1,18 @@
+/* Converting between GValues and Go values */
+
+GValue *gogi_value_new(GType type) {
+	GValue *value = g_new0(GValue, 1);
+	g_value_init(value, type);
+	return value;
+}
+
+GType gogi_value_type(const GValue *value) { return G_VALUE_TYPE(value); }
+GType gogi_value_fundamental(const GValue *value) { return G_TYPE_FUNDAMENTAL(G_VALUE_TYPE(value)); }
+GType gogi_type_fundamental(GType type) { return G_TYPE_FUNDAMENTAL(type); }
+
+// objects and instances of interfaces are held the same way
+gpointer gogi_value_get_instance(const GValue *value) { return value->data[0].v_pointer; }
+
+gchar **gogi_strv_new(gsize n) { return g_new0(gchar *, n + 1); }
+gchar *gogi_strv_get(gchar **strv, gsize i) { return strv[i]; }
+void gogi_strv_set(gchar **strv, gsize i, gchar *str) { strv[i] = str; }
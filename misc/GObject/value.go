@@ -0,0 +1,257 @@
+/* GValues hold Go values for the functions that take them */
+
+// Creates a GValue holding a Go value, with the GType that suits it: bool,
+// numbers, strings and []string get the matching fundamental types, objects
+// their instance's type, and enums, flags and boxed structs their own. Another
+// *Value is copied. It panics for anything else.
+func NewValue(v interface{}) *Value {
+	gtype, ok := valueType(v)
+	if !ok {
+		panic("NewValue: can't hold a " + reflect.TypeOf(v).String())
+	}
+	return NewValueOfType((int)(gtype), v)
+}
+
+// Creates a GValue of the given type holding a Go value, which is converted to
+// that type if it can be; numbers convert to each other and to enums and flags,
+// and other GValues are transformed. It panics if the value can't be converted.
+func NewValueOfType(gtype int, v interface{}) *Value {
+	value := wrapValue(C.gogi_value_new((C.GType)(gtype)), false)
+	if !setValue(value.ptr, v) {
+		panic("NewValueOfType: can't convert a " + reflect.TypeOf(v).String() + " to " + C.GoString((*C.char)(C.g_type_name((C.GType)(gtype)))))
+	}
+	return value
+}
+
+// Gets a GValue for a Go value handed to a function that takes one; a *Value is
+// used as it is
+func ToValue(v interface{}) *Value {
+	if value, ok := v.(*Value); ok {
+		return value
+	}
+	return NewValue(v)
+}
+
+// Gets the Go value a GValue holds, going by its fundamental type: bool,
+// numbers of the matching size, string, []string, unsafe.Pointer, or the
+// wrapper of an object, as the most derived type that has one. Enums come out
+// as int and flags as uint. Anything else, such as other boxed types, comes
+// out as the *Value itself.
+func (self *Value) Go() interface{} {
+	if self == nil {
+		return nil
+	}
+	value := self.ptr
+	switch C.gogi_value_fundamental(value) {
+		case C.G_TYPE_BOOLEAN:
+			return C.g_value_get_boolean(value) != 0
+		case C.G_TYPE_CHAR:
+			return (int8)(C.g_value_get_schar(value))
+		case C.G_TYPE_UCHAR:
+			return (uint8)(C.g_value_get_uchar(value))
+		case C.G_TYPE_INT:
+			return (int32)(C.g_value_get_int(value))
+		case C.G_TYPE_UINT:
+			return (uint32)(C.g_value_get_uint(value))
+		case C.G_TYPE_LONG:
+			return (int64)(C.g_value_get_long(value))
+		case C.G_TYPE_ULONG:
+			return (uint64)(C.g_value_get_ulong(value))
+		case C.G_TYPE_INT64:
+			return (int64)(C.g_value_get_int64(value))
+		case C.G_TYPE_UINT64:
+			return (uint64)(C.g_value_get_uint64(value))
+		case C.G_TYPE_FLOAT:
+			return (float32)(C.g_value_get_float(value))
+		case C.G_TYPE_DOUBLE:
+			return (float64)(C.g_value_get_double(value))
+		case C.G_TYPE_ENUM:
+			return (int)(C.g_value_get_enum(value))
+		case C.G_TYPE_FLAGS:
+			return (uint)(C.g_value_get_flags(value))
+		case C.G_TYPE_STRING:
+			return C.GoString((*C.char)(C.g_value_get_string(value)))
+		case C.G_TYPE_POINTER:
+			return unsafe.Pointer(C.g_value_get_pointer(value))
+		case C.G_TYPE_OBJECT, C.G_TYPE_INTERFACE:
+			ptr := C.gogi_value_get_instance(value)
+			if ptr == nil {
+				return nil
+			}
+			return wrapObject((*C.GObject)(ptr), false)
+		case C.G_TYPE_BOXED:
+			if C.gogi_value_type(value) == C.g_strv_get_type() {
+				strv := (**C.gchar)(C.g_value_get_boxed(value))
+				strs := make([]string, 0)
+				for i := 0; strv != nil && C.gogi_strv_get(strv, (C.gsize)(i)) != nil; i++ {
+					strs = append(strs, C.GoString((*C.char)(C.gogi_strv_get(strv, (C.gsize)(i)))))
+				}
+				return strs
+			}
+	}
+	return self
+}
+
+// Gets the GType that suits a Go value, as for NewValue
+func valueType(v interface{}) (C.GType, bool) {
+	switch v := v.(type) {
+		case nil:
+			return C.G_TYPE_POINTER, true
+		case *Value:
+			return C.gogi_value_type(v.ptr), true
+		case bool:
+			return C.G_TYPE_BOOLEAN, true
+		case int8:
+			return C.G_TYPE_CHAR, true
+		case uint8:
+			return C.G_TYPE_UCHAR, true
+		case int16, int32:
+			return C.G_TYPE_INT, true
+		case uint16, uint32:
+			return C.G_TYPE_UINT, true
+		case int, int64:
+			return C.G_TYPE_INT64, true
+		case uint, uint64:
+			return C.G_TYPE_UINT64, true
+		case float32:
+			return C.G_TYPE_FLOAT, true
+		case float64:
+			return C.G_TYPE_DOUBLE, true
+		case string:
+			return C.G_TYPE_STRING, true
+		case []string:
+			return C.g_strv_get_type(), true
+		case unsafe.Pointer:
+			return C.G_TYPE_POINTER, true
+		case interface{ GType() int; Native() unsafe.Pointer }:
+			gtype := (C.GType)(v.GType())
+			switch C.gogi_type_fundamental(gtype) {
+				case C.G_TYPE_OBJECT, C.G_TYPE_INTERFACE:
+					if v.Native() != nil {
+						return C.gogi_instance_type((C.gpointer)(v.Native())), true
+					}
+			}
+			return gtype, true
+		case interface{ GType() int }:
+			return (C.GType)(v.GType()), true
+	}
+	return 0, false
+}
+
+// Puts a Go value into an initialized GValue, converting it to the GValue's
+// type; ok is false if it can't be
+func setValue(value *C.GValue, v interface{}) bool {
+	if src, ok := v.(*Value); ok {
+		return C.g_value_transform(src.ptr, value) != 0
+	}
+	rv := reflect.ValueOf(v)
+	fundamental := C.gogi_value_fundamental(value)
+	switch fundamental {
+		case C.G_TYPE_BOOLEAN:
+			if rv.Kind() != reflect.Bool {
+				return false
+			}
+			if rv.Bool() {
+				C.g_value_set_boolean(value, 1)
+			} else {
+				C.g_value_set_boolean(value, 0)
+			}
+			return true
+		case C.G_TYPE_STRING:
+			if rv.Kind() != reflect.String {
+				return false
+			}
+			c_str := (*C.gchar)(C.CString(rv.String()))
+			defer C.g_free((C.gpointer)(c_str))
+			C.g_value_set_string(value, c_str)
+			return true
+		case C.G_TYPE_POINTER:
+			if v == nil {
+				C.g_value_set_pointer(value, nil)
+				return true
+			}
+			if p, ok := v.(unsafe.Pointer); ok {
+				C.g_value_set_pointer(value, (C.gpointer)(p))
+				return true
+			}
+			return false
+		case C.G_TYPE_OBJECT, C.G_TYPE_INTERFACE:
+			if v == nil {
+				C.g_value_set_object(value, nil)
+				return true
+			}
+			if ob, ok := v.(interface{ Native() unsafe.Pointer }); ok {
+				C.g_value_set_object(value, (C.gpointer)(ob.Native()))
+				return true
+			}
+			return false
+		case C.G_TYPE_BOXED:
+			if v == nil {
+				C.g_value_set_boxed(value, nil)
+				return true
+			}
+			if strs, ok := v.([]string); ok && C.gogi_value_type(value) == C.g_strv_get_type() {
+				strv := C.gogi_strv_new((C.gsize)(len(strs)))
+				for i, str := range strs {
+					C.gogi_strv_set(strv, (C.gsize)(i), (*C.gchar)(C.CString(str)))
+				}
+				C.g_value_take_boxed(value, (C.gconstpointer)(strv))
+				return true
+			}
+			if boxed, ok := v.(interface{ Native() unsafe.Pointer }); ok {
+				C.g_value_set_boxed(value, (C.gconstpointer)(boxed.Native()))
+				return true
+			}
+			return false
+	}
+	return setNumber(value, fundamental, rv)
+}
+
+// Puts a Go number into a GValue holding a number, enum or flags, converting it
+func setNumber(value *C.GValue, fundamental C.GType, rv reflect.Value) bool {
+	var i int64
+	var u uint64
+	var f float64
+	switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i = rv.Int()
+			u, f = (uint64)(i), (float64)(i)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			u = rv.Uint()
+			i, f = (int64)(u), (float64)(u)
+		case reflect.Float32, reflect.Float64:
+			f = rv.Float()
+			i, u = (int64)(f), (uint64)(f)
+		default:
+			return false
+	}
+	switch fundamental {
+		case C.G_TYPE_CHAR:
+			C.g_value_set_schar(value, (C.gint8)(i))
+		case C.G_TYPE_UCHAR:
+			C.g_value_set_uchar(value, (C.guchar)(u))
+		case C.G_TYPE_INT:
+			C.g_value_set_int(value, (C.gint)(i))
+		case C.G_TYPE_UINT:
+			C.g_value_set_uint(value, (C.guint)(u))
+		case C.G_TYPE_LONG:
+			C.g_value_set_long(value, (C.glong)(i))
+		case C.G_TYPE_ULONG:
+			C.g_value_set_ulong(value, (C.gulong)(u))
+		case C.G_TYPE_INT64:
+			C.g_value_set_int64(value, (C.gint64)(i))
+		case C.G_TYPE_UINT64:
+			C.g_value_set_uint64(value, (C.guint64)(u))
+		case C.G_TYPE_FLOAT:
+			C.g_value_set_float(value, (C.gfloat)(f))
+		case C.G_TYPE_DOUBLE:
+			C.g_value_set_double(value, (C.gdouble)(f))
+		case C.G_TYPE_ENUM:
+			C.g_value_set_enum(value, (C.gint)(i))
+		case C.G_TYPE_FLAGS:
+			C.g_value_set_flags(value, (C.guint)(u))
+		default:
+			return false
+	}
+	return true
+}
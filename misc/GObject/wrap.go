@@ -0,0 +1,42 @@
+/* Wrapping instances in the implementation of their most derived type */
+
+var wrapperMutex sync.Mutex
+var wrappers = make(map[string]func() interface{})
+
+// Registers the implementation of an object type, by its GType's name, for
+// wrapping instances of it, and of types derived from it that have none of
+// their own. Generated packages register all of theirs when they're loaded;
+// newImpl only allocates one, such as new(gtk.ButtonImpl).
+func RegisterWrapper(typeName string, newImpl func() interface{}) {
+	wrapperMutex.Lock()
+	defer wrapperMutex.Unlock()
+	wrappers[typeName] = newImpl
+}
+
+// Creates the implementation of the most derived type of an instance that has
+// one registered and is also a gtype, or returns nil if none is. The instance
+// isn't set yet; the generated wrap<Type> functions do that. Go subclasses
+// have none, so their instances get their nearest generated ancestor's; the
+// Go values themselves come from SubclassInstance.
+func NewWrapper(ptr unsafe.Pointer, gtype int) interface{} {
+	wrapperMutex.Lock()
+	defer wrapperMutex.Unlock()
+	for t := C.gogi_instance_type((C.gpointer)(ptr)); t != 0; t = C.g_type_parent(t) {
+		if C.g_type_is_a(t, (C.GType)(gtype)) == 0 {
+			// and neither are its ancestors
+			return nil
+		}
+		if newImpl, ok := wrappers[C.GoString((*C.char)(C.g_type_name(t)))]; ok {
+			return newImpl()
+		}
+	}
+	return nil
+}
+
+// Checks whether an instance is of a type, derives from it or implements it
+func IsA(ptr unsafe.Pointer, gtype int) bool {
+	if ptr == nil {
+		return false
+	}
+	return C.g_type_is_a(C.gogi_instance_type((C.gpointer)(ptr)), (C.GType)(gtype)) != 0
+}
@@ -0,0 +1,44 @@
+/* Common C code across all generated bindings; it's static, since every package has its own */
+
+static inline gpointer gogi_callback_pointer(guint id) { return GUINT_TO_POINTER(id); }
+static inline guint gogi_callback_id(gpointer data) { return GPOINTER_TO_UINT(data); }
+
+static inline gpointer gogi_array_new(gsize n, gsize size, gboolean zero_terminated) {
+	return g_malloc0((n + (zero_terminated ? 1 : 0)) * size);
+}
+
+static inline GHashTable *gogi_hash_table_new(gboolean strings) {
+	if (strings) {
+		return g_hash_table_new(g_str_hash, g_str_equal);
+	}
+	return g_hash_table_new(NULL, NULL);
+}
+
+static inline gpointer gogi_int_pointer(gint i) { return GINT_TO_POINTER(i); }
+static inline gint gogi_pointer_int(gpointer p) { return GPOINTER_TO_INT(p); }
+
+static inline void gogi_set_vfunc(gpointer klass, guint offset, GCallback fn) {
+	G_STRUCT_MEMBER(GCallback, klass, offset) = fn;
+}
+
+// strings C doesn't take are kept by the instance instead
+static inline void gogi_keep_string(gpointer instance, const gchar *key, gchar *str) {
+	g_object_set_data_full(instance, key, str, g_free);
+}
+
+static gboolean gogi_unref_idle_dispatch(gpointer data) { return G_SOURCE_REMOVE; }
+
+// GTK and GDK instances only work from the thread running the main context;
+// the rest can be released from any thread
+static inline void gogi_unref_later(gpointer instance, GDestroyNotify unref) {
+	GType type;
+	for (type = G_TYPE_FROM_INSTANCE(instance); type != 0; type = g_type_parent(type)) {
+		const gchar *name = g_type_name(type);
+		if (g_str_has_prefix(name, "Gtk") || g_str_has_prefix(name, "Gdk")) {
+			// the source's destroy notify is what releases it
+			g_idle_add_full(G_PRIORITY_DEFAULT_IDLE, gogi_unref_idle_dispatch, instance, unref);
+			return;
+		}
+	}
+	unref(instance);
+}
@@ -1,6 +1,7 @@
 /* Common Go code across all generated bindings */
 
 type GError struct {
+	Domain uint32
 	Code int
 	Message string
 }
@@ -9,3 +10,162 @@ func (self GError) Error() string {
 	// ???: include error code?
 	return self.Message
 }
+
+// The enums of error domains implement this, so their values can be compared
+// to GErrors from any package
+type errorCode interface {
+	ErrorDomain() uint32
+	ErrorCode() int
+}
+
+type errorCodeTarget interface {
+	SetErrorCode(domain uint32, code int) bool
+}
+
+func (self GError) Is(target error) bool {
+	switch target := target.(type) {
+		case errorCode:
+			return self.Domain == target.ErrorDomain() && self.Code == target.ErrorCode()
+		case GError:
+			return self.Domain == target.Domain && self.Code == target.Code
+	}
+	return false
+}
+
+func (self GError) As(target interface{}) bool {
+	if target, ok := target.(errorCodeTarget); ok {
+		return target.SetErrorCode(self.Domain, self.Code)
+	}
+	return false
+}
+
+// Copies a GError from C and frees it
+func errorFromC(c_error *C.GError) GError {
+	defer C.g_error_free(c_error)
+	return GError{(uint32)(c_error.domain), (int)(c_error.code), C.GoString((*C.char)(c_error.message))}
+}
+
+func errorQuark(domain string) uint32 {
+	c_domain := (*C.gchar)(C.CString(domain))
+	defer C.g_free((C.gpointer)(c_domain))
+	return (uint32)(C.g_quark_from_string(c_domain))
+}
+
+/* Go funcs handed to C are kept here; C only ever sees their handle */
+
+type callbackEntry struct {
+	fn interface{}
+	once bool
+	// the last string it returned that C didn't take
+	kept *C.gchar
+}
+
+var callbackMutex sync.Mutex
+var callbackNext C.guint
+var callbackRegistry = make(map[C.guint]callbackEntry)
+
+func callbackAdd(fn interface{}, once bool) C.gpointer {
+	callbackMutex.Lock()
+	defer callbackMutex.Unlock()
+	callbackNext++
+	callbackRegistry[callbackNext] = callbackEntry{fn, once, nil}
+	return C.gogi_callback_pointer(callbackNext)
+}
+
+func callbackGet(data C.gpointer) interface{} {
+	callbackMutex.Lock()
+	defer callbackMutex.Unlock()
+	id := C.gogi_callback_id(data)
+	entry := callbackRegistry[id]
+	if entry.once {
+		delete(callbackRegistry, id)
+	}
+	return entry.fn
+}
+
+func callbackRemove(data C.gpointer) {
+	callbackMutex.Lock()
+	defer callbackMutex.Unlock()
+	id := C.gogi_callback_id(data)
+	C.g_free((C.gpointer)(callbackRegistry[id].kept))
+	delete(callbackRegistry, id)
+}
+
+// Keeps a string a Go func returned to C that it doesn't take, until the func
+// returns another or is removed. Funcs that are only called once are already
+// gone by then, so theirs are left for C.
+func callbackKeep(data C.gpointer, str *C.gchar) {
+	callbackMutex.Lock()
+	defer callbackMutex.Unlock()
+	id := C.gogi_callback_id(data)
+	entry, ok := callbackRegistry[id]
+	if !ok {
+		return
+	}
+	C.g_free((C.gpointer)(entry.kept))
+	entry.kept = str
+	callbackRegistry[id] = entry
+}
+
+/* Overrides of vfuncs that must chain up are tracked, so they can be made to */
+
+type chainUpKey struct {
+	instance C.gpointer
+	vfunc string
+}
+
+// each call that's under way has a flag, innermost last, since overrides can
+// end up calling themselves on the same instance
+var chainUpMutex sync.Mutex
+var chainedUp = make(map[chainUpKey][]bool)
+
+func chainUpBegin(instance C.gpointer, vfunc string) {
+	chainUpMutex.Lock()
+	defer chainUpMutex.Unlock()
+	key := chainUpKey{instance, vfunc}
+	chainedUp[key] = append(chainedUp[key], false)
+}
+
+func chainUpMark(instance C.gpointer, vfunc string) {
+	chainUpMutex.Lock()
+	defer chainUpMutex.Unlock()
+	if calls := chainedUp[chainUpKey{instance, vfunc}]; len(calls) > 0 {
+		calls[len(calls) - 1] = true
+	}
+}
+
+// Checks whether the innermost override chained up since its chainUpBegin, and
+// stops tracking it
+func chainUpDone(instance C.gpointer, vfunc string) bool {
+	chainUpMutex.Lock()
+	defer chainUpMutex.Unlock()
+	key := chainUpKey{instance, vfunc}
+	calls := chainedUp[key]
+	if len(calls) == 0 {
+		return false
+	}
+	done := calls[len(calls) - 1]
+	if len(calls) == 1 {
+		delete(chainedUp, key)
+	} else {
+		chainedUp[key] = calls[:len(calls) - 1]
+	}
+	return done
+}
+
+// Keeps a string returned to C that it doesn't take until the next one for the
+// same key replaces it, or the instance is finalized
+func keepString(instance C.gpointer, key string, str *C.gchar) {
+	c_key := (*C.gchar)(C.CString(key))
+	defer C.g_free((C.gpointer)(c_key))
+	C.gogi_keep_string(instance, c_key, str)
+}
+
+// Releases the reference a collected wrapper held. Finalizers run on a
+// goroutine of their own, so GTK and GDK instances, which can only be released
+// from the main thread, are released from the default main context instead.
+func unrefLater(ptr unsafe.Pointer, unref C.GDestroyNotify) {
+	if ptr != nil {
+		C.gogi_unref_later((C.gpointer)(ptr), unref)
+	}
+}
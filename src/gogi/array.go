@@ -0,0 +1,210 @@
+package gogi
+
+import (
+	"fmt"
+)
+
+// How the GLib array types are laid out and released
+type arrayKind struct {
+	ctype string
+	data string
+	unref string
+	// clears the function that frees the elements along with the array
+	unsetFree string
+}
+
+var arrayKinds = map[int]arrayKind {
+	(int)(GArray):    {"GArray", "data", "g_array_unref", "g_array_set_clear_func"},
+	(int)(PtrArray):  {"GPtrArray", "pdata", "g_ptr_array_unref", "g_ptr_array_set_free_func"},
+	(int)(ByteArray): {"GByteArray", "data", "g_byte_array_unref", ""},
+}
+
+// Gets the C variable that holds the length of an array argument, which is
+// another argument of the same callable
+func arrayLengthVar(arg Argument) string {
+	callable := arg.info
+	if callable.Type == Arg {
+		callable = arg.info.GetContainer() ; defer callable.Free()
+	}
+	length := callable.GetArg(arg.typ.GetArrayLength()) ; defer length.Free()
+	return "c_" + length.GetName()
+}
+
+// Gets the value that ends a zero-terminated array, or "" if there isn't one
+func arrayTerminator(elemType *GiInfo) string {
+	_, p := CType(elemType)
+	if p != "" || isCallback(elemType) {
+		return "nil"
+	}
+	if _, ok := gvalueTypes[(int)(elemType.GetTag())]; ok {
+		return "0"
+	}
+	return ""
+}
+
+// Copies a Go slice into a newly allocated C array, GArray, GPtrArray or
+// GByteArray. The array is freed after the call unless the callee takes it,
+// and its elements are marshaled the same way as single values, so they're
+// freed unless the callee takes those too.
+func marshalArrayToC(arg Argument) (ctype string, marshal string) {
+	typeInfo := arg.typ
+	govar := noKeywords(arg.name)
+	cvar := arg.cname
+	elemType := typeInfo.GetParamType(0) ; defer elemType.Free()
+	if elemType.GetTag() == ArrayTag {
+		return "", ""
+	}
+
+	elemArg := Argument{arg.info, elemType, In, govar + "_el", cvar + "_ar[i]", ""}
+	elemCType, elemMarshal := MarshalToC(elemArg)
+	if elemCType == "" || elemCType == "C." {
+		return "", ""
+	}
+	if isEmbedded(elemType) {
+		// the array holds the structs themselves, not pointers to them
+		elemCType = elemCType[1:]
+		elemMarshal = fmt.Sprintf("%s = *(%s).ptr", elemArg.cname, elemArg.name)
+	}
+	sizeof := "C.sizeof_gpointer"
+	if elemCType[0] != '*' {
+		sizeof = "C.sizeof_" + elemCType[2:]
+	}
+
+	length := fmt.Sprintf("len(%s)", govar)
+	var alloc, free string
+	data := cvar
+	arrayType := typeInfo.GetArrayType()
+	if arrayType == CArray {
+		ctype = "*" + elemCType
+		if size := typeInfo.GetArrayFixedSize(); size != -1 {
+			length = fmt.Sprint(size)
+		}
+		zeroTerminated := 0
+		if typeInfo.IsZeroTerminated() {
+			zeroTerminated = 1
+		}
+		alloc = fmt.Sprintf("%s = (%s)(C.gogi_array_new((C.gsize)(%s_n), (C.gsize)(%s), %d))", cvar, ctype, cvar, sizeof, zeroTerminated)
+		free = fmt.Sprintf("C.g_free((C.gpointer)(%s))", cvar)
+	} else {
+		kind, ok := arrayKinds[(int)(arrayType)]
+		if !ok || (arrayType == PtrArray && sizeof != "C.sizeof_gpointer") {
+			return "", ""
+		}
+		ctype = "*C." + kind.ctype
+		switch arrayType {
+			case GArray:
+				alloc = fmt.Sprintf("%s = C.g_array_sized_new(0, 1, (C.guint)(%s), (C.guint)(%s_n))\n", cvar, sizeof, cvar)
+				alloc += fmt.Sprintf("\tC.g_array_set_size(%s, (C.guint)(%s_n))", cvar, cvar)
+			case PtrArray:
+				alloc = fmt.Sprintf("%s = C.g_ptr_array_sized_new((C.guint)(%s_n))\n", cvar, cvar)
+				alloc += fmt.Sprintf("\tC.g_ptr_array_set_size(%s, (C.gint)(%s_n))", cvar, cvar)
+			case ByteArray:
+				alloc = fmt.Sprintf("%s = C.g_byte_array_sized_new((C.guint)(%s_n))\n", cvar, cvar)
+				alloc += fmt.Sprintf("\tC.g_byte_array_set_size(%s, (C.guint)(%s_n))", cvar, cvar)
+		}
+		data = cvar + "." + kind.data
+		free = fmt.Sprintf("C.%s(%s)", kind.unref, cvar)
+	}
+
+	marshal += fmt.Sprintf("%s_n := %s\n", cvar, length)
+	marshal += "\t" + alloc + "\n"
+	marshal += fmt.Sprintf("\t%s_ar := (*[1 << 28]%s)((C.gpointer)(%s))\n", cvar, elemCType, data)
+	marshal += fmt.Sprintf("\tfor i, %s := range %s {\n", elemArg.name, govar)
+	marshal += fmt.Sprintf("\t\tif i == %s_n {\n", cvar)
+	marshal += "\t\t\tbreak\n"
+	marshal += "\t\t}\n"
+	marshal += "\t\t" + indent(elemMarshal) + "\n"
+	marshal += "\t}"
+	if arg.dir == In && getTransfer(arg) == Nothing {
+		marshal += "\n\tdefer " + free
+	}
+	return
+}
+
+// Copies a C array, GArray, GPtrArray or GByteArray into a Go slice. The
+// length of a C array comes from its fixed size, its length argument or its
+// terminator. The array is freed afterwards if it was handed over, along
+// with its elements if those were too.
+func marshalArrayToGo(arg Argument) (gotype string, marshal string) {
+	typeInfo := arg.typ
+	govar := arg.name
+	cvar := arg.cname
+	eq := ":="
+	if arg.dir == InOut {
+		eq = "="
+	}
+	// in-out arrays were marshaled to C in the same function, which used this name
+	ar := cvar + "_ar"
+	if arg.dir == InOut {
+		ar = cvar + "_back"
+	}
+	elemType := typeInfo.GetParamType(0) ; defer elemType.Free()
+	if elemType.GetTag() == ArrayTag {
+		return "", ""
+	}
+
+	elemArg := Argument{arg.info, elemType, In, govar + "_el", cvar + "_el", ""}
+	elemGoType, elemMarshal := MarshalToGo(elemArg)
+	elemCType, cp := CType(elemType)
+	if elemGoType == "" || elemCType == "" {
+		return "", ""
+	}
+	gotype = "[]" + elemGoType
+	elemCType = cp + "C." + elemCType
+
+	var data, length, free string
+	arrayType := typeInfo.GetArrayType()
+	if arrayType == CArray {
+		data = cvar
+		if size := typeInfo.GetArrayFixedSize(); size != -1 {
+			length = fmt.Sprintf("%s_n = %d", govar, size)
+		} else if typeInfo.GetArrayLength() != -1 && arg.info != nil {
+			length = fmt.Sprintf("%s_n = (int)(%s)", govar, arrayLengthVar(arg))
+		} else if zero := arrayTerminator(elemType); typeInfo.IsZeroTerminated() && zero != "" {
+			length = fmt.Sprintf("for %s[%s_n] != %s {\n", ar, govar, zero) +
+			         fmt.Sprintf("\t\t\t%s_n++\n", govar) +
+			         "\t\t}"
+		} else {
+			// no way to tell how long it is
+			return "", ""
+		}
+		if getTransfer(arg) != Nothing {
+			free = fmt.Sprintf("C.g_free((C.gpointer)(%s))", cvar)
+		}
+	} else {
+		kind, ok := arrayKinds[(int)(arrayType)]
+		if !ok || (arrayType == PtrArray && cp == "") {
+			return "", ""
+		}
+		data = cvar + "." + kind.data
+		length = fmt.Sprintf("%s_n = (int)(%s.len)", govar, cvar)
+		switch getTransfer(arg) {
+			case Container:
+				// its free func, if it has one, is left to take care of the elements
+				free = fmt.Sprintf("C.%s(%s)", kind.unref, cvar)
+			case Everything:
+				// the elements are ours now, so they mustn't be freed along with it
+				if kind.unsetFree != "" {
+					free = fmt.Sprintf("C.%s(%s, nil)\n\t", kind.unsetFree, cvar)
+				}
+				free += fmt.Sprintf("C.%s(%s)", kind.unref, cvar)
+		}
+	}
+
+	marshal += fmt.Sprintf("var %s *[1 << 28]%s\n", ar, elemCType)
+	marshal += fmt.Sprintf("\t%s_n := 0\n", govar)
+	marshal += fmt.Sprintf("\tif %s != nil {\n", cvar)
+	marshal += fmt.Sprintf("\t\t%s = (*[1 << 28]%s)((C.gpointer)(%s))\n", ar, elemCType, data)
+	marshal += "\t\t" + length + "\n"
+	marshal += "\t}\n"
+	marshal += fmt.Sprintf("\t%s %s make(%s, %s_n)\n", govar, eq, gotype, govar)
+	marshal += fmt.Sprintf("\tfor i := range %s {\n", govar)
+	marshal += fmt.Sprintf("\t\t%s := %s[i]\n", elemArg.cname, ar)
+	marshal += "\t\t" + indent(elemMarshal) + "\n"
+	marshal += fmt.Sprintf("\t\t%s[i] = %s\n", govar, elemArg.name)
+	marshal += "\t}"
+	if free != "" {
+		marshal += "\n\t" + free
+	}
+	return
+}
@@ -0,0 +1,294 @@
+package gogi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The user data and destroy notify arguments that go along with a callback argument
+type callbackArg struct {
+	closure int
+	destroy int
+	scope ScopeType
+}
+
+var goExports string
+
+// Exported Go code; cgo doesn't allow it in the same file as the C wrappers
+func GetExports() string {
+	return goExports
+}
+
+// Gets the name C knows an exported Go function by. Exports share one
+// namespace across a binary, so each package puts its name in its own.
+func exportName(name string) string {
+	return "gogi_" + PackageName(cNamespace) + "_" + name
+}
+
+// Writes the destroy notify that releases the Go funcs handed to C along with
+// callbacks. Every package has its own copy.
+func WriteCallbackSupport() (c string) {
+	export := exportName("callback_destroy")
+	c += fmt.Sprintf("extern void %s(gpointer data);\n", export)
+
+	goExports += fmt.Sprintf("//export %s\n", export)
+	goExports += fmt.Sprintf("func %s(data C.gpointer) {\n", export)
+	goExports += "\tcallbackRemove(data)\n"
+	goExports += "}\n\n"
+	return
+}
+
+func isCallback(typeInfo *GiInfo) bool {
+	if typeInfo.GetTag() != InterfaceTag {
+		return false
+	}
+	interfaceInfo := typeInfo.GetTypeInterface() ; defer interfaceInfo.Free()
+	return interfaceInfo.Type == Callback
+}
+
+func isCallbackData(callbacks map[string]callbackArg, n int) bool {
+	for _, cb := range callbacks {
+		if cb.closure == n || cb.destroy == n {
+			return true
+		}
+	}
+	return false
+}
+
+// Finds the arguments that belong to the callback argument n of a function
+func getCallbackArg(info *GiInfo, n int) callbackArg {
+	arg := info.GetArg(n) ; defer arg.Free()
+	cb := callbackArg{arg.GetClosure(), arg.GetDestroy(), arg.GetScope()}
+
+	// older annotations put the closure on the user data instead of the callback
+	if cb.closure == -1 {
+		for i := 0; i < info.GetNArgs(); i++ {
+			other := info.GetArg(i)
+			if i != n && other.GetClosure() == n {
+				cb.closure = i
+			}
+			other.Free()
+		}
+	}
+	return cb
+}
+
+// Gets the index of the user data argument of a callback type, or -1 if there isn't one
+func callbackUserData(info *GiInfo) int {
+	argc := info.GetNArgs()
+	for i := 0; i < argc; i++ {
+		arg := info.GetArg(i)
+		closure := arg.GetClosure()
+		arg.Free()
+		if closure == i {
+			return i
+		}
+	}
+
+	// not annotated, so fall back on the usual naming
+	for i := argc - 1; i >= 0; i-- {
+		arg := info.GetArg(i)
+		name := arg.GetName()
+		arg.Free()
+		if name == "user_data" {
+			return i
+		}
+	}
+	return -1
+}
+
+// Gets the Go func type for a callback, or "" if it can't be marshaled
+func callbackSignature(info *GiInfo) string {
+	userData := callbackUserData(info)
+	if userData == -1 {
+		return ""
+	}
+
+	params := make([]string, 0)
+	for i := 0; i < info.GetNArgs(); i++ {
+		if i == userData {
+			continue
+		}
+		arg := info.GetArg(i) ; defer arg.Free()
+		typ := arg.GetType() ; defer typ.Free()
+		// TODO: out arguments and callbacks taking callbacks
+		if arg.GetDirection() != In || isCallback(typ) {
+			return ""
+		}
+		gotype, gp := GoType(typ)
+		ctype, _ := CType(typ)
+		if gotype == "" || ctype == "" || blacklist[gotype] {
+			return ""
+		}
+		params = append(params, fmt.Sprintf("%s %s", noKeywords(arg.GetName()), gp + gotype))
+	}
+
+	signature := "func(" + strings.Join(params, ", ") + ")"
+
+	returnType := info.GetReturnType() ; defer returnType.Free()
+	if returnType.GetTag() != VoidTag || returnType.IsPointer() {
+		if isCallback(returnType) {
+			return ""
+		}
+		gotype, gp := GoType(returnType)
+		if gotype == "" || blacklist[gotype] {
+			return ""
+		}
+		signature += " " + gp + gotype
+	}
+	return signature
+}
+
+// Writes the user data (and destroy notify) that carry a callback argument through C
+func callbackDataMarshal(info *GiInfo, arg Argument, cb callbackArg) (g string) {
+	data := info.GetArg(cb.closure) ; defer data.Free()
+	cdata := "c_" + data.GetName()
+
+	once := "false"
+	if cb.scope == Async {
+		// only ever invoked once, so the trampoline drops it
+		once = "true"
+	}
+	g += fmt.Sprintf("\tvar %s C.gpointer\n", cdata)
+	g += fmt.Sprintf("\t%s = callbackAdd(%s, %s)\n", cdata, noKeywords(arg.name), once)
+	switch cb.scope {
+		case Invalid, Call:
+			// only valid for the duration of the call
+			g += fmt.Sprintf("\tdefer callbackRemove(%s)\n", cdata)
+	}
+
+	if cb.destroy != -1 {
+		destroy := info.GetArg(cb.destroy) ; defer destroy.Free()
+		cdestroy := "c_" + destroy.GetName()
+		g += fmt.Sprintf("\tvar %s C.GDestroyNotify\n", cdestroy)
+		g += fmt.Sprintf("\t%s = (C.GDestroyNotify)(C.%s)\n", cdestroy, exportName("callback_destroy"))
+	}
+	return
+}
+
+// Writes the function that C calls into for a callback type
+func callbackTrampoline(info *GiInfo) (g string, c string) {
+	return writeTrampoline(callbackTrampolineName(info), info, nil, callbackUserData(info), goTypeName(info))
+}
+
+// Gets the name of the trampoline for a callback type. The Go funcs are kept
+// by the package they're passed to, so one that takes another package's
+// callbacks has trampolines of its own for them, with its name in theirs.
+func callbackTrampolineName(info *GiInfo) string {
+	name := "callback_" + GetPrefix(info) + info.GetName()
+	if isImported(info) {
+		return PackageName(cNamespace) + "_" + name
+	}
+	return name
+}
+
+// Writes a C-callable function for the callable info that looks up a Go func of
+// type fnType from its user data and invokes it. The exported half goes into
+// goExports and the rest into g. When self is given, the instance is passed in
+// first; a userData of -1 means it's an extra argument at the end, as for signals.
+func writeTrampoline(name string, info *GiInfo, self *GiInfo, userData int, fnType string) (g string, c string) {
+	export := "gogi_" + name
+	if cExports[export] {
+		return
+	}
+	cExports[export] = true
+
+	returnType := info.GetReturnType() ; defer returnType.Free()
+	returns := returnType.GetTag() != VoidTag || returnType.IsPointer()
+
+	cParamLine := make([]string, 0)
+	gParamLine := make([]string, 0)
+	cArgLine := make([]string, 0)
+	gArgLine := make([]string, 0)
+	var marshal string
+	if self != nil {
+		ctype := GetPrefix(self) + self.GetName()
+		cParamLine = append(cParamLine, ctype + " *self")
+		gParamLine = append(gParamLine, "c_self *C." + ctype)
+		cArgLine = append(cArgLine, "c_self")
+		marshal += fmt.Sprintf("\tself := wrap%s(c_self, false)\n", self.GetName())
+		gArgLine = append(gArgLine, "self")
+	}
+
+	cParams, gParams, cArgs, gArgs, m, data := trampolineParams(info, userData)
+	cParamLine = append(cParamLine, cParams...)
+	gParamLine = append(gParamLine, gParams...)
+	cArgLine = append(cArgLine, cArgs...)
+	gArgLine = append(gArgLine, gArgs...)
+	marshal += m
+	if userData == -1 {
+		cParamLine = append(cParamLine, "gpointer user_data")
+		gParamLine = append(gParamLine, "c_user_data C.gpointer")
+		cArgLine = append(cArgLine, "c_user_data")
+	}
+
+	cret, gret := "void ", ""
+	if returns {
+		ctype, cp := CType(returnType)
+		cret = ctype + " " + cp
+		gret = " " + cp + "C." + ctype
+	}
+
+	params := strings.Join(gParamLine, ", ")
+	c += fmt.Sprintf("extern %s%s(%s);\n", cret, export, strings.Join(cParamLine, ", "))
+
+	goExports += fmt.Sprintf("//export %s\n", export)
+	goExports += fmt.Sprintf("func %s(%s)%s {\n", export, params, gret)
+	if returns {
+		goExports += fmt.Sprintf("\treturn %s(%s)\n", name, strings.Join(cArgLine, ", "))
+	} else {
+		goExports += fmt.Sprintf("\t%s(%s)\n", name, strings.Join(cArgLine, ", "))
+	}
+	goExports += "}\n\n"
+
+	g += fmt.Sprintf("func %s(%s)%s {\n", name, params, gret)
+	g += fmt.Sprintf("\tfn := callbackGet(%s).(%s)\n", data, fnType)
+	g += marshal
+	g += "\t"
+	if returns {
+		g += "retval := "
+	}
+	g += fmt.Sprintf("fn(%s)\n", strings.Join(gArgLine, ", "))
+	if returns {
+		// it outlives the call, so nothing it's marshaled into is freed when it returns
+		retArg := Argument{info, returnType, Out, "retval", "c_retval", ""}
+		ctype, m := MarshalToC(retArg)
+		g += fmt.Sprintf("\tvar c_retval %s\n", ctype)
+		g += fmt.Sprintf("\t%s\n", m)
+		switch returnType.GetTag() {
+			case Utf8Tag, FilenameTag:
+				if getTransfer(retArg) == Nothing {
+					// C doesn't free it, so it's kept along with the Go func
+					g += fmt.Sprintf("\tcallbackKeep(%s, (*C.gchar)(c_retval))\n", data)
+				}
+		}
+		g += "\treturn c_retval\n"
+	}
+	g += "}\n"
+	return
+}
+
+// Gets the C and Go parameters of a trampoline for the callable info's
+// arguments, the arguments to pass on to C and Go, and the marshaling of the Go
+// ones. The userData argument is passed on to C but not to Go, and data is
+// the variable holding it.
+func trampolineParams(info *GiInfo, userData int) (cParamLine, gParamLine, cArgLine, gArgLine []string, marshal string, data string) {
+	data = "c_user_data"
+	for i := 0; i < info.GetNArgs(); i++ {
+		arg := info.GetArg(i) ; defer arg.Free()
+		typ := arg.GetType()
+		argName := arg.GetName()
+		ctype, cp := CType(typ)
+		cParamLine = append(cParamLine, fmt.Sprintf("%s %s", ctype, cp + argName))
+		gParamLine = append(gParamLine, fmt.Sprintf("c_%s %sC.%s", argName, cp, ctype))
+		cArgLine = append(cArgLine, "c_" + argName)
+		if i == userData {
+			data = "c_" + argName
+			continue
+		}
+		_, m := MarshalToGo(Argument{arg, typ, In, noKeywords(argName), "c_" + argName, ""})
+		marshal += "\t" + m + "\n"
+		gArgLine = append(gArgLine, noKeywords(argName))
+	}
+	return
+}
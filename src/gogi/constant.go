@@ -0,0 +1,76 @@
+package gogi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Writes each of an object's or interface's constants
+func writeConstants(info *GiInfo) (g string) {
+	constant_count, getConstant := info.GetNConstants(), info.GetConstant
+	if info.Type == Interface {
+		constant_count, getConstant = info.GetNInterfaceConstants(), info.GetInterfaceConstant
+	}
+	for i := 0; i < constant_count; i++ {
+		constant := getConstant(i) ; defer constant.Free()
+		if constant.IsDeprecated() {
+			continue
+		}
+		g += WriteConstant(constant, info)
+	}
+	return
+}
+
+// Writes a typed Go const, with the value and type read from the typelib.
+// Names are mostly in upper case in C, e.g. MAJOR_VERSION becomes MajorVersion,
+// but the rest keep their case, as KEY_a and KEY_A are different constants.
+func WriteConstant(info *GiInfo, owner *GiInfo) (g string) {
+	name := constantName(info.GetName())
+	if owner != nil {
+		name = owner.GetName() + name
+	}
+	if blacklist[info.GetName()] {
+		return
+	}
+	if goFuncs[name] {
+		println("skipping constant", info.GetName() + ":", name, "is taken")
+		return
+	}
+
+	typ := info.GetConstantType() ; defer typ.Free()
+	gotype, _ := GoType(typ)
+	if gotype == "" {
+		return
+	}
+
+	var value string
+	switch v := info.GetConstantValue().(type) {
+		case bool:
+			value = strconv.FormatBool(v)
+		case int64:
+			value = strconv.FormatInt(v, 10)
+		case uint64:
+			value = strconv.FormatUint(v, 10)
+		case float64:
+			value = strconv.FormatFloat(v, 'g', -1, 64)
+		case string:
+			value = strconv.Quote(v)
+		default:
+			return
+	}
+
+	goFuncs[name] = true
+	return fmt.Sprintf("const %s %s = %s\n", name, gotype, value)
+}
+
+// Converts a constant's C name to Go, title casing the parts in upper case
+func constantName(cname string) (name string) {
+	for _, part := range strings.Split(cname, "_") {
+		if part == strings.ToUpper(part) {
+			part = strings.Title(strings.ToLower(part))
+		}
+		name += part
+	}
+	return
+}
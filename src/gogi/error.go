@@ -0,0 +1,52 @@
+package gogi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Makes the enum of an error domain usable as an error itself. Its values
+// match GErrors from that domain with errors.Is, and errors.As sets one from
+// such a GError.
+func writeErrorDomain(info *GiInfo) (g string) {
+	domain := info.GetErrorDomain()
+	if domain == "" || info.Type != Enum {
+		return
+	}
+	name := info.GetName()
+	quark := strings.ToLower(name[0:1]) + name[1:] + "Quark"
+
+	g += fmt.Sprintf("var %s = errorQuark(\"%s\")\n", quark, domain)
+
+	g += fmt.Sprintf("func (code %s) Error() string {\n", name)
+	g += "\tswitch code {\n"
+	seen := make(map[int64]bool)
+	value_count := info.GetNEnumValues()
+	for i := 0; i < value_count; i++ {
+		value := info.GetEnumValue(i) ; defer value.Free()
+		if seen[value.GetValue()] {
+			continue
+		}
+		seen[value.GetValue()] = true
+		g += fmt.Sprintf("\t\tcase %s:\n", enumValueName(name, CamelCase(value.GetName())))
+		g += fmt.Sprintf("\t\t\treturn \"%s: %s\"\n", domain, value.GetName())
+	}
+	g += "\t}\n"
+	g += fmt.Sprintf("\treturn \"%s\"\n", domain)
+	g += "}\n"
+
+	g += fmt.Sprintf("func (code %s) ErrorDomain() uint32 {\n", name)
+	g += fmt.Sprintf("\treturn %s\n", quark)
+	g += "}\n"
+	g += fmt.Sprintf("func (code %s) ErrorCode() int {\n", name)
+	g += "\treturn (int)(code)\n"
+	g += "}\n"
+	g += fmt.Sprintf("func (code *%s) SetErrorCode(domain uint32, c int) bool {\n", name)
+	g += fmt.Sprintf("\tif domain != %s {\n", quark)
+	g += "\t\treturn false\n"
+	g += "\t}\n"
+	g += fmt.Sprintf("\t*code = %s(c)\n", name)
+	g += "\treturn true\n"
+	g += "}\n"
+	return
+}
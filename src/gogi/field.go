@@ -0,0 +1,170 @@
+package gogi
+
+import (
+	"fmt"
+)
+
+// nested structs and unions live inside their owner instead of being pointed to
+func isEmbedded(typeInfo *GiInfo) bool {
+	if typeInfo.GetTag() != InterfaceTag || typeInfo.IsPointer() {
+		return false
+	}
+	interfaceInfo := typeInfo.GetTypeInterface() ; defer interfaceInfo.Free()
+	return interfaceInfo.Type == Struct || interfaceInfo.Type == Union
+}
+
+// an instance struct embedding its parent, which As<Parent>() already covers
+func isParentInstance(typeInfo *GiInfo) bool {
+	if typeInfo.GetTag() != InterfaceTag || typeInfo.IsPointer() {
+		return false
+	}
+	interfaceInfo := typeInfo.GetTypeInterface() ; defer interfaceInfo.Free()
+	return interfaceInfo.Type == Object
+}
+
+// Writes accessors for each of a struct's or object's fields
+func writeFields(info *GiInfo) (g string, c string) {
+	field_count, getField := info.GetNStructFields(), info.GetStructField
+	if info.Type == Object {
+		field_count, getField = info.GetNObjectFields(), info.GetObjectField
+	}
+	for i := 0; i < field_count; i++ {
+		field := getField(i) ; defer field.Free()
+		g_, c_ := WriteField(field, info)
+		g += g_
+		c += c_
+	}
+	return
+}
+
+// Writes the methods Get<Field>() for readable fields and Set<Field>(value) for
+// writable ones, backed by C functions that access the field. Nested structs
+// are handed out by reference, so changes to them show up in the owner, and are
+// copied in when set. Fixed-size arrays are copied to and from Go slices.
+func WriteField(info *GiInfo, owner *GiInfo) (g string, c string) {
+	ownerName := owner.GetName()
+	cowner := GetPrefix(owner) + ownerName
+	fieldName := info.GetName()
+	flags := info.GetFieldFlags()
+	typ := info.GetFieldType() ; defer typ.Free()
+
+	selfPtr := "self.ptr"
+	if owner.Type == Object {
+		selfPtr = fmt.Sprintf("self.As%s()", ownerName)
+	}
+
+	// for fixed-size arrays, the accessors work on one element at a time
+	elemType := typ
+	size := -1
+	index, cindex, gindex := "", "", ""
+	if typ.GetTag() == ArrayTag {
+		size = typ.GetArrayFixedSize()
+		if typ.GetArrayType() != CArray || size <= 0 {
+			return
+		}
+		elemType = typ.GetParamType(0) ; defer elemType.Free()
+		index, cindex, gindex = ", gint i", "[i]", ", (C.gint)(i)"
+	}
+	if isCallback(elemType) || isParentInstance(elemType) || elemType.GetTag() == ArrayTag {
+		return
+	}
+
+	gotype, gp := GoType(elemType)
+	ctype, cp := CType(elemType)
+	if gotype == "" || ctype == "" || blacklist[gotype] {
+		return
+	}
+	embedded := isEmbedded(elemType)
+	goFieldType := gp + gotype
+	if size != -1 {
+		goFieldType = "[]" + goFieldType
+	}
+
+	getter := ownerName + "Get" + CamelCase(fieldName)
+	if flags.Readable && !goFuncs[getter] {
+		cfunc := fmt.Sprintf("gogi_%s_get_%s", cowner, fieldName)
+		var marshal string
+		var ok bool
+		if embedded {
+			c += fmt.Sprintf("%s *%s(%s *self%s) {\n", ctype, cfunc, cowner, index)
+			c += fmt.Sprintf("\treturn &self->%s%s;\n", fieldName, cindex)
+			c += "}\n"
+			marshal, ok = fmt.Sprintf("elem := &%s{c_elem}", gotype), true
+			interfaceInfo := elemType.GetTypeInterface() ; defer interfaceInfo.Free()
+			if isImported(interfaceInfo) {
+				marshal = fmt.Sprintf("elem := %s(unsafe.Pointer(c_elem))", wrapFunc(interfaceInfo))
+				// the wrappers of boxed types free what they hold
+				ok = !isBoxed(interfaceInfo)
+			}
+		} else {
+			var retType string
+			retType, marshal = MarshalToGo(Argument{info, elemType, In, "elem", "c_elem", ""})
+			if ok = retType != ""; ok {
+				c += fmt.Sprintf("%s %s%s(%s *self%s) {\n", ctype, cp, cfunc, cowner, index)
+				c += fmt.Sprintf("\treturn self->%s%s;\n", fieldName, cindex)
+				c += "}\n"
+			}
+		}
+
+		if ok {
+			signature := fmt.Sprintf("%s() %s", methodName(owner, "Get" + CamelCase(fieldName)), goFieldType)
+			g += fmt.Sprintf("func (self %s) %s {\n", selfType(owner), signature)
+			if size != -1 {
+				g += fmt.Sprintf("\tretval := make(%s, %d)\n", goFieldType, size)
+				g += "\tfor i := range retval {\n"
+				g += fmt.Sprintf("\t\tc_elem := C.%s(%s%s)\n", cfunc, selfPtr, gindex)
+				g += "\t\t" + marshal + "\n"
+				g += "\t\tretval[i] = elem\n"
+				g += "\t}\n"
+			} else {
+				g += fmt.Sprintf("\tc_elem := C.%s(%s)\n", cfunc, selfPtr)
+				g += "\t" + marshal + "\n"
+				g += "\tretval := elem\n"
+			}
+			g += "\treturn retval\n"
+			g += "}\n"
+			goFuncs[getter] = true
+			addMethod(owner, signature)
+		}
+	}
+
+	setter := ownerName + "Set" + CamelCase(fieldName)
+	if flags.Writable && !goFuncs[setter] {
+		cfunc := fmt.Sprintf("gogi_%s_set_%s", cowner, fieldName)
+		govar := "value"
+		if size != -1 {
+			govar = "value[i]"
+		}
+		argType, marshal := MarshalToC(Argument{info, elemType, In, govar, "c_elem", ""})
+		if embedded {
+			argType = "*C." + ctype
+			c += fmt.Sprintf("void %s(%s *self%s, %s *value) {\n", cfunc, cowner, index, ctype)
+			c += fmt.Sprintf("\tself->%s%s = *value;\n", fieldName, cindex)
+			c += "}\n"
+		} else if argType != "" && argType != "C." {
+			c += fmt.Sprintf("void %s(%s *self%s, %s %svalue) {\n", cfunc, cowner, index, ctype, cp)
+			c += fmt.Sprintf("\tself->%s%s = value;\n", fieldName, cindex)
+			c += "}\n"
+		} else {
+			return
+		}
+
+		signature := fmt.Sprintf("%s(value %s)", methodName(owner, "Set" + CamelCase(fieldName)), goFieldType)
+		g += fmt.Sprintf("func (self %s) %s {\n", selfType(owner), signature)
+		indent := "\t"
+		if size != -1 {
+			g += fmt.Sprintf("\tfor i := 0; i < len(value) && i < %d; i++ {\n", size)
+			indent = "\t\t"
+		}
+		g += fmt.Sprintf("%svar c_elem %s\n", indent, argType)
+		g += indent + marshal + "\n"
+		g += fmt.Sprintf("%sC.%s(%s%s, c_elem)\n", indent, cfunc, selfPtr, gindex)
+		if size != -1 {
+			g += "\t}\n"
+		}
+		g += "}\n"
+		goFuncs[setter] = true
+		addMethod(owner, signature)
+	}
+	return
+}
@@ -14,14 +14,53 @@ type Argument struct {
 	marshal string
 }
 
-// return a marshaled Go function and any necessary C wrapper
+// return a marshaled Go function and any necessary C wrapper;
+// methods are written as methods of their owner's wrapper, and
+// constructors as New<Type> functions that return the type they construct.
+// Functions with optional out-params also get a <Name>SkipOptional variant,
+// which passes NULL for them and leaves them out of its results.
 func WriteFunction(info *GiInfo, owner *GiInfo) (g string, c string) {
+	g, c = writeFunction(info, owner, false)
+	if g == "" || !hasOptionalOuts(info) {
+		return
+	}
+	// it calls the same C wrapper
+	g_, _ := writeFunction(info, owner, true)
+	if g_ != "" {
+		g += "\n" + g_
+	}
+	return
+}
+
+// Checks whether a function has any out-params that C can be passed NULL for,
+// other than ones that are left out anyway since they can't be marshaled
+func hasOptionalOuts(info *GiInfo) bool {
+	for i := 0; i < info.GetNArgs(); i++ {
+		arg := info.GetArg(i) ; defer arg.Free()
+		if arg.GetDirection() != Out || !arg.IsOptional() {
+			continue
+		}
+		typ := arg.GetType() ; defer typ.Free()
+		gotype, _ := GoType(typ)
+		ctype, _ := CType(typ)
+		if gotype != "" && ctype != "" && !blacklist[gotype] {
+			return true
+		}
+	}
+	return false
+}
+
+func writeFunction(info *GiInfo, owner *GiInfo, skipOptional bool) (g string, c string) {
 	symbol := info.GetSymbol()
-	if blacklist[symbol] || cExports[symbol] {
+	if blacklist[symbol] || (cExports[symbol] && !skipOptional) {
 		return
 	}
 	cExports[symbol] = true
 	prefix := GetPrefix(info)
+	var suffix string
+	if skipOptional {
+		suffix = "SkipOptional"
+	}
 
 	flags := info.GetFunctionFlags()
 	argc := info.GetNArgs()
@@ -58,23 +97,60 @@ func WriteFunction(info *GiInfo, owner *GiInfo) (g string, c string) {
 		c += ctype + " " + cp
 	}
 
-	if owner != nil {
-		g += ownerName
+	goName := ownerName + CamelCase(info.GetName()) + suffix
+	if owner != nil && flags.IsConstructor {
+		goName = constructorName(owner, info.GetName()) + suffix
 	}
-	g += CamelCase(info.GetName()) + "("
-	c += "gogi_" + symbol + "("
-
 	cParamLine := make([]string, 0)
 	gParamLine := make([]string, 0)
 
+	// where its signature starts, if it's a method
+	signatureStart := -1
 	if owner != nil && flags.IsMethod {
+		// a method of the wrapper, which its subtypes pick up too
+		g += fmt.Sprintf("(self %s) ", selfType(owner))
+		signatureStart = len(g)
+		g += methodName(owner, CamelCase(info.GetName())) + suffix + "("
 		cParamLine = append(cParamLine, prefix + ownerName + " *self")
-		gArg := "self "
-		if owner.Type == Struct {
-			gArg += "*"
+	} else {
+		g += goName + "("
+	}
+	c += "gogi_" + symbol + "("
+
+	// arguments that are implied by others and so are left out of the Go signature
+	hidden := make(map[int]bool)
+	callbacks := make(map[string]callbackArg)
+	// destroy notifies are callbacks themselves, so find the callbacks' data first
+	callbackData := make(map[int]bool)
+	for i := 0; i < argAndRetc; i++ {
+		arg := info.GetArg(i)
+		if isCallback(arg.GetType()) {
+			cb := getCallbackArg(info, i)
+			callbackData[cb.closure] = true
+			callbackData[cb.destroy] = true
+		}
+	}
+	for i := 0; i < argAndRetc; i++ {
+		arg := info.GetArg(i)
+		if length := arg.GetType().GetArrayLength(); length != -1 {
+			hidden[length] = true
+		}
+		if callbackData[i] || !isCallback(arg.GetType()) {
+			continue
+		}
+		cb := getCallbackArg(info, i)
+		if cb.closure == -1 {
+			// no way to get the Go func back out in the trampoline
+			return "", ""
+		}
+		hidden[cb.closure] = true
+		if cb.destroy != -1 {
+			hidden[cb.destroy] = true
 		}
-		gArg += ownerName
-		gParamLine = append(gParamLine, gArg)
+		callbacks[arg.GetName()] = cb
+	}
+	if length := returnType.GetArrayLength(); length != -1 {
+		hidden[length] = true
 	}
 
 	arrayArgs := make([]*GiInfo, 0) // so we can ignore array length parameters
@@ -87,12 +163,30 @@ func WriteFunction(info *GiInfo, owner *GiInfo) (g string, c string) {
 		arg := info.GetArg(i)
 		dir := arg.GetDirection()
 		typ := arg.GetType()
+		if isCallbackData(callbacks, i) {
+			// user data and destroy notify are filled in along with their callback
+			ctype, cp := CType(typ)
+			argsAndRets = append(argsAndRets, Argument{arg,typ,dir,arg.GetName(),"c_"+arg.GetName(),""})
+			cParamLine = append(cParamLine, fmt.Sprintf("%s %s", ctype, cp + arg.GetName()))
+			continue
+		}
 		gotype, gp := GoType(typ)
 		ctype, cp := CType(typ)
-		if gotype == "" || ctype == "" || blacklist[gotype] {
+		if gotype == "" || ctype == "" || blacklist[gotype] || (skipOptional && dir == Out && arg.IsOptional()) {
+			if dir == Out && arg.IsOptional() {
+				// C doesn't need somewhere to put it, so leave it out
+				argsAndRets = append(argsAndRets, Argument{arg,typ,In,arg.GetName(),"nil",""})
+				cParamLine = append(cParamLine, "gpointer " + arg.GetName())
+				retc--
+				continue
+			}
 			// argument failed to marshal
 			return "", ""
 		}
+		if dir == In && isNullableString(arg, typ) {
+			// so that nil can stand for NULL
+			gp = "*"
+		}
 
 		array_length := typ.GetArrayLength()
 		if array_length != -1 {
@@ -120,27 +214,42 @@ func WriteFunction(info *GiInfo, owner *GiInfo) (g string, c string) {
 			if needsConst(arg, typ, ctype, cp) {
 				ctype = "const " + ctype
 			}
-			gParamLine = append(gParamLine, fmt.Sprintf("%s %s", noKeywords(name), gp + gotype))
+			if !hidden[i] {
+				gParamLine = append(gParamLine, fmt.Sprintf("%s %s", noKeywords(name), gp + gotype))
+			}
 			cParamLine = append(cParamLine, fmt.Sprintf("%s %s", ctype, cp + name))
 		} else if dir == Out {
-			rets = append(rets, newArg)
-			cp += "*"
+			if hidden[i] {
+				retc--
+			} else {
+				rets = append(rets, newArg)
+			}
+			if !(isValue(typ) && arg.IsCallerAllocates()) {
+				// except where C fills in one of ours, which it's already a pointer to
+				cp += "*"
+			}
 			cParamLine = append(cParamLine, fmt.Sprintf("%s %s", ctype, cp + name))
 		} else if dir == InOut {
 			args = append(args, newArg)
-			rets = append(rets, newArg)
+			if hidden[i] {
+				retc--
+			} else {
+				rets = append(rets, newArg)
+			}
 			cp += "*"
-			gParamLine = append(gParamLine, fmt.Sprintf("%s %s", noKeywords(name), gp + gotype))
+			if !hidden[i] {
+				gParamLine = append(gParamLine, fmt.Sprintf("%s %s", noKeywords(name), gp + gotype))
+			}
 			cParamLine = append(cParamLine, fmt.Sprintf("%s %s", ctype, cp + name))
 		}
 	}
-	var arrayArgOffset int
 	for _, arg := range arrayArgs {
-		// TODO: update this if methods actually become invoked from the object
-		a := arg.GetType().GetArrayLength() + 1 - arrayArgOffset
-		arrayLengthMarshal += fmt.Sprintf("\t%s := len(%s)\n", info.GetArg(a-1).GetName(), arg.GetName())
-		gParamLine = append(gParamLine[:a], gParamLine[a+1:]...)
-		arrayArgOffset++
+		if arg.GetDirection() == Out {
+			// its length comes back from C along with it
+			continue
+		}
+		length := info.GetArg(arg.GetType().GetArrayLength())
+		arrayLengthMarshal += fmt.Sprintf("\t%s := len(%s)\n", noKeywords(length.GetName()), noKeywords(arg.GetName()))
 	}
 	if flags.Throws {
 		cParamLine = append(cParamLine, "GError **error")
@@ -150,20 +259,36 @@ func WriteFunction(info *GiInfo, owner *GiInfo) (g string, c string) {
 
 	var returns bool
 	if returnType.GetTag() != VoidTag || returnType.IsPointer() {
+		if gotype, _ := GoType(returnType); gotype == "" {
+			return "", ""
+		}
 		retc++
-		rets = append(rets, Argument{nil,returnType,In,"retval","c_retval",""})
+		rets = append(rets, Argument{info,returnType,In,"retval","c_retval",""})
 		returns = true
 	}
 
 	gParamLine = make([]string, 0)
+	retNames := make([]string, len(rets))
 	for i, ret := range rets {
+		retNames[i] = ret.name
 		retType, retMarshal := MarshalToGo(ret)
 		if retType == "" {
 			return "", ""
 		}
+		if ret.info == info && constructs(info, owner) {
+			// it's declared as returning an ancestor, but it's always one of these
+			retType = ownerName
+			retMarshal = fmt.Sprintf("retval := wrap%s(C.%s((C.gpointer)(c_retval)), %t)", ownerName, castFunc(prefix, ownerName, &c), getTransfer(ret) == Everything)
+		}
 		if blacklist[strings.Trim(retType, "*")] {
 			return "", ""
 		}
+		if isNullableString(ret.info, ret.typ) {
+			// NULL comes back as ("", false)
+			retType += ", bool"
+			retMarshal = fmt.Sprintf("%s_ok := %s != nil\n\t", ret.name, ret.cname) + retMarshal
+			retNames[i] += ", " + ret.name + "_ok"
+		}
 		gParamLine = append(gParamLine, retType)
 		rets[i].marshal = retMarshal
 	}
@@ -173,6 +298,10 @@ func WriteFunction(info *GiInfo, owner *GiInfo) (g string, c string) {
 	if len(gParamLine) > 0 {
 		g += "(" + strings.Join(gParamLine, ", ") + ") "
 	}
+	var signature string
+	if signatureStart != -1 {
+		signature = strings.TrimSpace(g[signatureStart:])
+	}
 
 	g += "{\n"
 	c += "{\n"
@@ -186,15 +315,31 @@ func WriteFunction(info *GiInfo, owner *GiInfo) (g string, c string) {
 			return "", ""
 		}
 		g += fmt.Sprintf("\tvar %s %s\n", arg.cname, ctype)
-		g += fmt.Sprintf("\t%s\n", marshal)
+		if arg.dir == In && arg.info.MayBeNull() && (isNilable(arg.typ) || isNullableString(arg.info, arg.typ)) {
+			// nil is left as NULL
+			if isNullableString(arg.info, arg.typ) {
+				deref := arg
+				deref.name = "(*" + noKeywords(arg.name) + ")"
+				_, marshal = MarshalToC(deref)
+			}
+			g += fmt.Sprintf("\tif %s != nil {\n", noKeywords(arg.name))
+			g += fmt.Sprintf("\t\t%s\n", indent(marshal))
+			g += "\t}\n"
+		} else {
+			g += fmt.Sprintf("\t%s\n", marshal)
+		}
+		if cb, ok := callbacks[arg.name]; ok {
+			g += callbackDataMarshal(info, arg, cb)
+		}
 	}
 
-	for i, ret := range rets {
-		if i == len(rets)-1 && returns {
-			break
-		}
+	for _, ret := range argsAndRets {
 		if ret.dir == Out {
 			ctype, cp := CType(ret.typ)
+			if isValue(ret.typ) && ret.info.IsCallerAllocates() {
+				// C fills in one of ours
+				cp = ""
+			}
 			/*
 			if ret.info.IsCallerAllocates() && cp != "" {
 				cp = cp[1:]
@@ -215,15 +360,15 @@ func WriteFunction(info *GiInfo, owner *GiInfo) (g string, c string) {
 	gParamLine = make([]string, 0)
 	if owner != nil && flags.IsMethod {
 		switch owner.Type {
-			case Object:
+			case Object, Interface:
 				gParamLine = append(gParamLine, fmt.Sprintf("self.As%s()", ownerName))
-			case Struct:
+			case Struct, Union:
 				gParamLine = append(gParamLine, "self.ptr")
 		}
 	}
 	for _, arg := range argsAndRets {
 		name := arg.cname
-		if arg.dir == Out {
+		if arg.dir == Out || arg.dir == InOut {
 			name = "&" + name
 		}
 		gParamLine = append(gParamLine, name)
@@ -237,12 +382,9 @@ func WriteFunction(info *GiInfo, owner *GiInfo) (g string, c string) {
 		g += "\t" + ret.marshal + "\n"
 	}
 	if retc > 0 || flags.Throws {
-		gParamLine = make([]string, 0)
-		for _, ret := range rets {
-			gParamLine = append(gParamLine, ret.name)
-		}
+		gParamLine = retNames
 		if flags.Throws {
-			e := "GError{Code:(int)((*c_error).code), Message:C.GoString((*C.char)((*c_error).message))}"
+			e := "errorFromC(c_error)"
 			g += "\tif c_error != nil {\n"
 			g += "\t\treturn " + strings.Join(append(gParamLine, e), ", ") + "\n"
 			g += "\t}\n"
@@ -275,6 +417,23 @@ func WriteFunction(info *GiInfo, owner *GiInfo) (g string, c string) {
 	g += "}\n"
 	c += "}\n"
 
+	// other packages' callback types need trampolines of this package's
+	for _, arg := range args {
+		if _, ok := callbacks[arg.name]; !ok {
+			continue
+		}
+		callback := arg.typ.GetTypeInterface() ; defer callback.Free()
+		if isImported(callback) {
+			g_, c_ := callbackTrampoline(callback)
+			g += g_
+			c = c_ + c
+		}
+	}
+
+	goFuncs[goName] = true
+	if signature != "" {
+		addMethod(owner, signature)
+	}
 	return
 }
 
@@ -295,6 +454,7 @@ func WriteStruct(info *GiInfo) (g string, c string) {
 	g += fmt.Sprintf("type %s struct {\n", name)
 	g += fmt.Sprintf("\tptr *C.%s\n", prefix + name)
 	g += "}\n"
+	g += writeStructWrap(info)
 
 	// do its methods
 	method_count := info.GetNStructMethods()
@@ -308,6 +468,11 @@ func WriteStruct(info *GiInfo) (g string, c string) {
 		c += c_ + "\n"
 	}
 
+	// and its fields, where they don't clash with methods
+	g_, c_ := writeFields(info)
+	g += g_
+	c += c_
+
 	g += "\n"
 	if c != "" {
 		c += "\n"
@@ -316,60 +481,307 @@ func WriteStruct(info *GiInfo) (g string, c string) {
 	return
 }
 
+// Writes a union as an opaque Go type like a struct, with accessors for its
+// fields; discriminated unions also get a GetActive method, which returns
+// whichever field the discriminator says is in use
+func WriteUnion(info *GiInfo) (g string, c string) {
+	name := info.GetName()
+
+	if blacklist[name] {
+		return
+	}
+
+	prefix := GetPrefix(info)
+
+	g += fmt.Sprintf("type %s struct {\n", name)
+	g += fmt.Sprintf("\tptr *C.%s\n", prefix + name)
+	g += "}\n"
+	g += writeStructWrap(info)
+
+	// do its methods
+	method_count := info.GetNUnionMethods()
+	for i := 0; i < method_count; i++ {
+		method := info.GetUnionMethod(i)
+		if method.IsDeprecated() {
+			continue
+		}
+		g_, c_ := WriteFunction(method, info)
+		g += g_ + "\n"
+		c += c_ + "\n"
+	}
+
+	// and its fields
+	getters := make(map[int64]string)
+	values := make([]int64, 0)
+	field_count := info.GetNUnionFields()
+	for i := 0; i < field_count; i++ {
+		field := info.GetUnionField(i) ; defer field.Free()
+		// it only has a getter if WriteField wrote one, rather than a method taking its name
+		key := name + "Get" + CamelCase(field.GetName())
+		taken := goFuncs[key]
+		g_, c_ := WriteField(field, info)
+		g += g_
+		c += c_
+		getter := methodName(info, "Get" + CamelCase(field.GetName()))
+		if info.IsDiscriminated() && !taken && goFuncs[key] {
+			value := info.GetDiscriminatorValue(i)
+			if _, ok := getters[value]; !ok {
+				values = append(values, value)
+			}
+			getters[value] = getter
+		}
+	}
+
+	if len(getters) > 0 {
+		discriminator := info.GetDiscriminatorType() ; defer discriminator.Free()
+		ctype, _ := CType(discriminator)
+		cfunc := fmt.Sprintf("gogi_%s_discriminator", prefix + name)
+		c += fmt.Sprintf("static %s %s(%s *self) {\n", ctype, cfunc, prefix + name)
+		c += fmt.Sprintf("\treturn *(%s*)((guint8*)self + %d);\n", ctype, info.GetDiscriminatorOffset())
+		c += "}\n"
+
+		g += fmt.Sprintf("func (self *%s) %s() interface{} {\n", name, methodName(info, "GetActive"))
+		g += fmt.Sprintf("\tswitch C.%s(self.ptr) {\n", cfunc)
+		for _, value := range values {
+			g += fmt.Sprintf("\t\tcase %d:\n", value)
+			g += fmt.Sprintf("\t\t\treturn self.%s()\n", getters[value])
+		}
+		g += "\t}\n"
+		g += "\treturn nil\n"
+		g += "}\n"
+	}
+
+	g += "\n"
+	if c != "" {
+		c += "\n"
+	}
+
+	return
+}
+
+// Writes an object type as a Go interface and an exported <Type>Impl struct
+// that implements it. The interface embeds its parent's, and the struct embeds
+// its parent's implementation along with those of the interfaces it adds, so
+// that methods, accessors and signals, which are all methods of the struct,
+// are inherited by subtypes.
 func WriteObject(info *GiInfo) (g string, c string) {
-	iter := info
-	name := iter.GetName()
+	name := info.GetName()
 
 	if blacklist[name] {
 		return
 	}
 
 	prefix := GetPrefix(info)
+	implName := GetImplName(name)
+
+	// the nearest ancestor that has a wrapper, and the interfaces it already has
+	var parent *GiInfo
+	implemented := make(map[string]bool)
+	for _, ancestor := range ancestors(info) {
+		defer ancestor.Free()
+		if parent == nil && (isImported(ancestor) || !blacklist[ancestor.GetName()]) {
+			parent = ancestor
+		}
+		interface_count := ancestor.GetNObjectInterfaces()
+		for i := 0; i < interface_count; i++ {
+			iface := ancestor.GetObjectInterface(i) ; defer iface.Free()
+			implemented[iface.GetNamespace() + "." + iface.GetName()] = true
+		}
+	}
+
+	// embedding an interface's implementation would hide any methods of its
+	// ancestors that share names with its own, so those only get As<Interface>().
+	// Other packages' C types can't be named here, so theirs are only embedded.
+	embeds := make([]*GiInfo, 0)
+	embedded := make(map[string]bool)
+	var members string
+	interface_count := info.GetNObjectInterfaces()
+	for i := 0; i < interface_count; i++ {
+		iface := info.GetObjectInterface(i) ; defer iface.Free()
+		ifaceName := iface.GetName()
+		if implemented[iface.GetNamespace() + "." + ifaceName] || (!isImported(iface) && blacklist[ifaceName]) {
+			continue
+		}
+		implemented[iface.GetNamespace() + "." + ifaceName] = true
+		embed := !embedded[ifaceName]
+		for _, member := range memberNames(iface) {
+			embed = embed && !reservedNames(info)[member]
+		}
+		if embed {
+			embeds = append(embeds, iface)
+			embedded[ifaceName] = true
+		} else if !isImported(iface) {
+			members += asMethod(implName, iface, &c)
+		}
+	}
+
+	// the implementations of interfaces embed those of the ones from other
+	// packages they require, which would be ambiguous with its parent's, so it
+	// embeds those itself as well
+	for _, iface := range embeds {
+		for _, prerequisite := range importedPrerequisites(iface) {
+			defer prerequisite.Free()
+			if embedded[prerequisite.GetName()] {
+				continue
+			}
+			embed := true
+			for _, member := range memberNames(prerequisite) {
+				embed = embed && !reservedNames(info)[member]
+			}
+			if embed {
+				embeds = append(embeds, prerequisite)
+				embedded[prerequisite.GetName()] = true
+			}
+		}
+	}
+
+	// do its methods
+	method_count := info.GetNObjectMethods()
+	for i := 0; i < method_count; i++ {
+		method := info.GetObjectMethod(i)
+		if method.IsDeprecated() {
+			continue
+		}
+		g_, c_ := WriteFunction(method, info)
+		members += g_ + "\n"
+		c += c_ + "\n"
+	}
+
+	// and its signals, properties, constants and public fields
+	g_, c_ := writeSignals(info)
+	members += g_
+	c += c_
+	g_, c_ = writeProperties(info)
+	members += g_
+	c += c_
+	members += writeConstants(info)
+	g_, c_ = writeFields(info)
+	members += g_
+	c += c_
 
 	// interface
 	g += fmt.Sprintf("type %s interface {\n", name)
+	if parent != nil {
+		g += fmt.Sprintf("\t%s\n", goTypeName(parent))
+	}
 	g += fmt.Sprintf("\tAs%s() *C.%s\n", name, prefix + name)
+	if parent == nil {
+		g += "\tNative() unsafe.Pointer\n"
+		g += "\tUnref()\n"
+		g += "\tClose() error\n"
+	}
+	g += methodSignatures(info)
 	g += "}\n"
 
 	// implementation
 	// ???: does it matter if it's abstract?
-	implName := GetImplName(name)
 	g += fmt.Sprintf("type %s struct {\n", implName)
-	g += fmt.Sprintf("\tptr *C.%s\n", prefix + name)
+	if parent != nil {
+		g += fmt.Sprintf("\t%sImpl\n", goTypeName(parent))
+	} else {
+		g += fmt.Sprintf("\tptr *C.%s\n", prefix + name)
+		if _, _, reftype := refFuncs(info); reftype == "" {
+			// set for the Go values of subclass instances, which belong to the instance
+			g += "\tborrowed bool\n"
+		}
+	}
+	for _, iface := range embeds {
+		g += fmt.Sprintf("\t%sImpl\n", goTypeName(iface))
+	}
 	g += "}\n"
+	g += writeObjectWrap(info, parent, embeds, &c)
+	g += members
+	g += writeVFuncs(info, parent, &c)
 
-	// ???: do this for abstract types?
-	for {
-		if !blacklist[prefix + name] {
-			cast := castFunc(prefix, name, &c)
-			g += fmt.Sprintf("func (ob %s) As%s() *C.%s {\n", implName, name, prefix + name)
-			g += fmt.Sprintf("\treturn C.%s((C.gpointer)(ob.ptr))\n", cast)
-			g += "}\n"
-		}
-		// ???: better way to tell when to stop?
-		if name == "Object" || name == "ParamSpec" {
-			break
+	g += "\n"
+	if c != "" {
+		c += "\n"
+	}
+
+	return
+}
+
+// Writes an interface type much like an object: a Go interface listing its
+// methods and an implementation, which wraps instances that come from C and
+// is embedded in the implementations of objects that implement it. Go types
+// registered with GObject's RegisterSubclass can embed it too, so the methods
+// those have of their own, like Native and GType, come from an unexported
+// type it embeds in turn, which loses out to an object type's.
+func WriteInterface(info *GiInfo) (g string, c string) {
+	name := info.GetName()
+
+	if blacklist[name] {
+		return
+	}
+
+	prefix := GetPrefix(info)
+	implName := GetImplName(name)
+
+	base := interfaceBaseName(name)
+
+	// anything implementing it must also be one of these. The implementation
+	// embeds those of the interfaces from other packages, whose C types can't be
+	// named here; objects from them already have their As<Object>() through
+	// their implementers' own types.
+	var members string
+	prerequisites := importedPrerequisites(info)
+	for _, prerequisite := range prerequisites {
+		defer prerequisite.Free()
+	}
+	prerequisite_count := info.GetNPrerequisites()
+	for i := 0; i < prerequisite_count; i++ {
+		prerequisite := info.GetPrerequisite(i) ; defer prerequisite.Free()
+		if isImported(prerequisite) || blacklist[prerequisite.GetName()] {
+			continue
 		}
-		// workaround for this sometimes being written out twice
-		oldName := name
-		for name == oldName {
-			iter = iter.GetParent() ; defer iter.Free()
-			name = iter.GetName()
+		switch prerequisite.Type {
+			case Object, Interface:
+				members += asMethod(base, prerequisite, &c)
 		}
 	}
 
 	// do its methods
-	method_count := info.GetNObjectMethods()
+	method_count := info.GetNInterfaceMethods()
 	for i := 0; i < method_count; i++ {
-		method := info.GetObjectMethod(i)
+		method := info.GetInterfaceMethod(i)
 		if method.IsDeprecated() {
 			continue
 		}
 		g_, c_ := WriteFunction(method, info)
-		g += g_ + "\n"
+		members += g_ + "\n"
 		c += c_ + "\n"
 	}
 
+	// and its signals, properties and constants
+	g_, c_ := writeSignals(info)
+	members += g_
+	c += c_
+	g_, c_ = writeProperties(info)
+	members += g_
+	c += c_
+	members += writeConstants(info)
+
+	g += fmt.Sprintf("type %s interface {\n", name)
+	g += fmt.Sprintf("\tAs%s() *C.%s\n", name, prefix + name)
+	g += "\tNative() unsafe.Pointer\n"
+	g += "\tUnref()\n"
+	g += "\tClose() error\n"
+	g += methodSignatures(info)
+	g += "}\n"
+
+	g += fmt.Sprintf("type %s struct {\n", base)
+	g += fmt.Sprintf("\tptr *C.%s\n", prefix + name)
+	g += "}\n"
+	g += fmt.Sprintf("type %s struct {\n", implName)
+	g += fmt.Sprintf("\t%s\n", base)
+	for _, prerequisite := range prerequisites {
+		g += fmt.Sprintf("\t%sImpl\n", goTypeName(prerequisite))
+	}
+	g += "}\n"
+	g += writeObjectWrap(info, nil, prerequisites, &c)
+	g += members
+	g += writeVFuncs(info, nil, &c)
+
 	g += "\n"
 	if c != "" {
 		c += "\n"
@@ -378,6 +790,26 @@ func WriteObject(info *GiInfo) (g string, c string) {
 	return
 }
 
+// Writes a callback type as a Go func type, plus the trampoline C calls it through
+func WriteCallback(info *GiInfo) (g string, c string) {
+	name := info.GetName()
+	if blacklist[name] {
+		return
+	}
+
+	signature := callbackSignature(info)
+	if signature == "" {
+		return
+	}
+	g += fmt.Sprintf("type %s %s\n", name, signature)
+
+	g_, c_ := callbackTrampoline(info)
+	g += g_ + "\n"
+	c += c_
+
+	return
+}
+
 func WriteEnum(info *GiInfo) (g string, c string) {
 	name := info.GetName()
 	prefix := GetPrefix(info)
@@ -385,13 +817,25 @@ func WriteEnum(info *GiInfo) (g string, c string) {
 	g += fmt.Sprintf("type %s C.%s\n", name, symbol)
 	g += "const (\n"
 
+	// error codes are typed, so they can be used as errors
+	var typ string
+	if info.GetErrorDomain() != "" {
+		typ = " " + name
+	}
 	value_count := info.GetNEnumValues()
 	for i := 0; i < value_count; i++ {
 		value := info.GetEnumValue(i) ; defer value.Free()
 		// ???: how to avoid name clashes?
-		g += fmt.Sprintf("\t%s = %d\n", enumValueName(name, CamelCase(value.GetName())), value.GetValue())
+		g += fmt.Sprintf("\t%s%s = %d\n", enumValueName(name, CamelCase(value.GetName())), typ, value.GetValue())
 	}
 	g += ")\n"
+	if init := info.GetRegisteredTypeInit(); init != "" && init != "intern" {
+		// for putting its values in a GValue
+		g += fmt.Sprintf("func (value %s) GType() int {\n", name)
+		g += fmt.Sprintf("\treturn (int)(C.%s())\n", init)
+		g += "}\n"
+	}
+	g += writeErrorDomain(info)
 
 	return
 }
@@ -407,17 +851,248 @@ func noKeywords(name string) string {
 	return name
 }
 
+// Writes wrap<Type>(ptr, owned) for an object or interface type, which wraps
+// an instance from C in the implementation of its most derived type that has
+// one, so it can be asserted to that type, or else in the type's own. Object
+// types register theirs with GObject's RegisterWrapper when the package loads.
+// The wrapper holds a reference of its own: it takes one unless it was handed
+// one, sinking floating references either way, and releases it when Unref is
+// called or once it's collected, from the main context for GTK and GDK
+// instances. Close does the same, for use with defer and io.Closer. Other
+// packages wrap instances through Wrap<Type>, and Cast<Type> gets a wrapper
+// from any package as the type if its instance is one, going by its GType.
+// The Go values of Go subclasses' instances are borrowed from the instance
+// instead, so for them Unref only releases a reference. Implementations also
+// get GType, for subclassing and implementing their types.
+//
+// Only the implementation of a root type holds the pointer; the others pass
+// Native, SetNative, Unref and Close down to their parent's. SetNative also
+// points the implementations of the interfaces they embed at the instance, as
+// does that of an interface that embeds those of its prerequisites.
+func writeObjectWrap(info *GiInfo, parent *GiInfo, embeds []*GiInfo, c *string) (g string) {
+	name := info.GetName()
+	prefix := GetPrefix(info)
+	ctype := "C." + prefix + name
+	implName := GetImplName(name)
+	// interfaces' own implementations only embed what has the shared methods
+	base := implName
+	if info.Type == Interface {
+		base = interfaceBaseName(name)
+	}
+	ref, unref, reftype := refFuncs(info)
+	gtype := gtypeFunc(info, c)
+
+	g += fmt.Sprintf("func wrap%s(ptr *%s, owned bool) %s {\n", name, ctype, name)
+	g += "\tif ptr == nil {\n"
+	g += "\t\treturn nil\n"
+	g += "\t}\n"
+	if reftype == "" {
+		g += "\tif !owned || C.g_object_is_floating((C.gpointer)(ptr)) != 0 {\n"
+		g += "\t\tC.g_object_ref_sink((C.gpointer)(ptr))\n"
+	} else {
+		g += "\tif !owned {\n"
+		g += "\t\t" + refCall(ref, reftype, "ptr") + "\n"
+	}
+	g += "\t}\n"
+	g += fmt.Sprintf("\tob, ok := %s(unsafe.Pointer(ptr), (int)(C.%s())).(%s)\n", runtimeFunc("GObject", "NewWrapper"), gtype, name)
+	g += "\tif !ok {\n"
+	g += fmt.Sprintf("\t\tob = new(%s)\n", implName)
+	g += "\t}\n"
+	g += "\tob.(interface{ SetNative(unsafe.Pointer) }).SetNative(unsafe.Pointer(ptr))\n"
+	g += fmt.Sprintf("\truntime.SetFinalizer(ob, func(ob %s) {\n", name)
+	g += fmt.Sprintf("\t\tunrefLater(ob.Native(), (C.GDestroyNotify)(C.%s))\n", unref)
+	g += "\t})\n"
+	g += "\treturn ob\n"
+	g += "}\n"
+
+	g += fmt.Sprintf("func Wrap%s(ptr unsafe.Pointer, owned bool) %s {\n", name, name)
+	g += fmt.Sprintf("\treturn wrap%s((*%s)(ptr), owned)\n", name, ctype)
+	g += "}\n"
+
+	g += fmt.Sprintf("func Cast%s(ob interface{ Native() unsafe.Pointer }) (%s, bool) {\n", name, name)
+	g += fmt.Sprintf("\tif ob == nil || !%s(ob.Native(), (int)(C.%s())) {\n", runtimeFunc("GObject", "IsA"), gtype)
+	g += "\t\treturn nil, false\n"
+	g += "\t}\n"
+	g += fmt.Sprintf("\tif cast, ok := ob.(%s); ok {\n", name)
+	g += "\t\treturn cast, true\n"
+	g += "\t}\n"
+	g += fmt.Sprintf("\treturn wrap%s((*%s)(ob.Native()), false), true\n", name, ctype)
+	g += "}\n"
+
+	if info.Type == Object {
+		g += "func init() {\n"
+		g += fmt.Sprintf("\t%s(\"%s\", func() interface{} { return new(%s) })\n", runtimeFunc("GObject", "RegisterWrapper"), info.GetRegisteredTypeName(), implName)
+		g += "}\n"
+	}
+
+	cast := castFunc(prefix, name, c)
+	g += fmt.Sprintf("func (ob *%s) As%s() *%s {\n", implName, name, ctype)
+	g += fmt.Sprintf("\treturn C.%s((C.gpointer)(ob.Native()))\n", cast)
+	g += "}\n"
+
+	g += fmt.Sprintf("func (ob *%s) GType() int {\n", base)
+	g += fmt.Sprintf("\treturn (int)(C.%s())\n", gtype)
+	g += "}\n"
+
+	if parent == nil {
+		g += fmt.Sprintf("func (ob *%s) Native() unsafe.Pointer {\n", base)
+		g += "\treturn unsafe.Pointer(ob.ptr)\n"
+		g += "}\n"
+		g += fmt.Sprintf("func (ob *%s) SetNative(ptr unsafe.Pointer) {\n", base)
+		g += fmt.Sprintf("\tob.ptr = (*%s)(ptr)\n", ctype)
+		g += "}\n"
+		g += fmt.Sprintf("func (ob *%s) Unref() {\n", base)
+		g += "\tif ob.ptr == nil {\n"
+		g += "\t\treturn\n"
+		g += "\t}\n"
+		g += "\t" + refCall(unref, reftype, "ob.ptr") + "\n"
+		if reftype == "" && info.Type == Object {
+			// the instance still has its Go value; this only released a reference
+			g += "\tif ob.borrowed {\n"
+			g += "\t\treturn\n"
+			g += "\t}\n"
+		}
+		g += "\tob.ptr = nil\n"
+		g += "}\n"
+		g += fmt.Sprintf("func (ob *%s) Close() error {\n", base)
+		g += "\tob.Unref()\n"
+		g += "\treturn nil\n"
+		g += "}\n"
+		if len(embeds) > 0 {
+			g += fmt.Sprintf("func (ob *%s) SetNative(ptr unsafe.Pointer) {\n", implName)
+			g += fmt.Sprintf("\tob.%s.SetNative(ptr)\n", base)
+			for _, iface := range embeds {
+				g += fmt.Sprintf("\tob.%s.SetNative(ptr)\n", GetImplName(iface.GetName()))
+			}
+			g += "}\n"
+		}
+		return
+	}
+
+	parentImpl := GetImplName(parent.GetName())
+	g += fmt.Sprintf("func (ob *%s) Native() unsafe.Pointer {\n", implName)
+	g += fmt.Sprintf("\treturn ob.%s.Native()\n", parentImpl)
+	g += "}\n"
+	g += fmt.Sprintf("func (ob *%s) SetNative(ptr unsafe.Pointer) {\n", implName)
+	g += fmt.Sprintf("\tob.%s.SetNative(ptr)\n", parentImpl)
+	for _, iface := range embeds {
+		g += fmt.Sprintf("\tob.%s.SetNative(ptr)\n", GetImplName(iface.GetName()))
+	}
+	g += "}\n"
+	g += fmt.Sprintf("func (ob *%s) Unref() {\n", implName)
+	g += fmt.Sprintf("\tob.%s.Unref()\n", parentImpl)
+	g += "}\n"
+	g += fmt.Sprintf("func (ob *%s) Close() error {\n", implName)
+	g += fmt.Sprintf("\treturn ob.%s.Close()\n", parentImpl)
+	g += "}\n"
+
+	// the interfaces' own As<Prerequisite>() would clash with each other and
+	// hide the ones it inherits; the ones from other packages it embeds instead
+	seen := map[string]bool{name: true}
+	for _, iface := range embeds {
+		prerequisite_count := iface.GetNPrerequisites()
+		for i := 0; i < prerequisite_count; i++ {
+			prerequisite := iface.GetPrerequisite(i) ; defer prerequisite.Free()
+			if seen[prerequisite.GetName()] || isImported(prerequisite) || blacklist[prerequisite.GetName()] {
+				continue
+			}
+			switch prerequisite.Type {
+				case Object, Interface:
+					seen[prerequisite.GetName()] = true
+					g += asMethod(implName, prerequisite, c)
+			}
+		}
+	}
+	return
+}
+
+// Writes wrap<Type>(ptr, copy) for a boxed struct or union, which wraps a
+// pointer from C and frees it once the Go value is collected. Pointers that C
+// still owns are copied first. Other packages get at it, and at the pointer
+// itself, through Wrap<Type> and Native; GType is for putting it in a GValue.
+func writeStructWrap(info *GiInfo) (g string) {
+	name := info.GetName()
+	ctype := "C." + GetPrefix(info) + name
+
+	g += fmt.Sprintf("func (self *%s) Native() unsafe.Pointer {\n", name)
+	g += "\treturn unsafe.Pointer(self.ptr)\n"
+	g += "}\n"
+	if !isBoxed(info) {
+		g += fmt.Sprintf("func Wrap%s(ptr unsafe.Pointer) *%s {\n", name, name)
+		g += "\tif ptr == nil {\n"
+		g += "\t\treturn nil\n"
+		g += "\t}\n"
+		g += fmt.Sprintf("\treturn &%s{(*%s)(ptr)}\n", name, ctype)
+		g += "}\n"
+		return
+	}
+
+	gtype := "C." + info.GetRegisteredTypeInit() + "()"
+	g += fmt.Sprintf("func wrap%s(ptr *%s, copy bool) *%s {\n", name, ctype, name)
+	g += "\tif ptr == nil {\n"
+	g += "\t\treturn nil\n"
+	g += "\t}\n"
+	g += "\tif copy {\n"
+	g += fmt.Sprintf("\t\tptr = (*%s)(C.g_boxed_copy(%s, (C.gconstpointer)(ptr)))\n", ctype, gtype)
+	g += "\t}\n"
+	g += fmt.Sprintf("\tself := &%s{ptr}\n", name)
+	g += fmt.Sprintf("\truntime.SetFinalizer(self, func(self *%s) {\n", name)
+	g += fmt.Sprintf("\t\tC.g_boxed_free(%s, (C.gpointer)(self.ptr))\n", gtype)
+	g += "\t})\n"
+	g += "\treturn self\n"
+	g += "}\n"
+	g += fmt.Sprintf("func Wrap%s(ptr unsafe.Pointer, copy bool) *%s {\n", name, name)
+	g += fmt.Sprintf("\treturn wrap%s((*%s)(ptr), copy)\n", name, ctype)
+	g += "}\n"
+	g += fmt.Sprintf("func (self *%s) GType() int {\n", name)
+	g += fmt.Sprintf("\treturn (int)(%s)\n", gtype)
+	g += "}\n"
+	return
+}
+
 // Gets the name for an enum value. Used to avoid naming conflicts
 func enumValueName(enum, value string) string {
 	return enum + value
 }
 
+// Writes the As<Type>() method that converts an implementation to the type it also is
+func asMethod(implName string, target *GiInfo, c *string) (g string) {
+	prefix := GetPrefix(target)
+	name := target.GetName()
+	cast := castFunc(prefix, name, c)
+	g += fmt.Sprintf("func (ob *%s) As%s() *C.%s {\n", implName, name, prefix + name)
+	g += fmt.Sprintf("\treturn C.%s((C.gpointer)(ob.Native()))\n", cast)
+	g += "}\n"
+	return
+}
+
+// Gets the interfaces from other namespaces an interface requires, whose
+// implementations its own embeds
+func importedPrerequisites(info *GiInfo) (prerequisites []*GiInfo) {
+	prerequisite_count := info.GetNPrerequisites()
+	for i := 0; i < prerequisite_count; i++ {
+		prerequisite := info.GetPrerequisite(i)
+		if prerequisite.Type != Interface || prerequisite.GetNamespace() == info.GetNamespace() {
+			prerequisite.Free()
+			continue
+		}
+		prerequisites = append(prerequisites, prerequisite)
+	}
+	return
+}
+
+// Gets the name of the C function for casting to a specific type; it has the
+// type's prefix, since types in different namespaces can share names
+func castName(prefix, n string) string {
+	return "as_" + strings.ToLower(prefix + n)
+}
+
 // Gets the C function for casting to a specific type and writes it if it hasn't been yet
 func castFunc(prefix, n string, c *string) string {
-	name := "as_" + strings.ToLower(n)
+	name := castName(prefix, n)
 	if !cExports[name] {
 		cExports[name] = true
-		(*c) += fmt.Sprintf("%s *%s(gpointer ob) {\n", prefix + n, name)
+		(*c) += fmt.Sprintf("static %s *%s(gpointer ob) {\n", prefix + n, name)
 		(*c) += fmt.Sprintf("\treturn (%s*)ob;\n", prefix + n)
 		(*c) += "}\n"
 	}
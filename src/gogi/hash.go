@@ -0,0 +1,109 @@
+package gogi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Gets the Go map type for a hash table, or "" if its keys or values can't be marshaled
+func hashGoType(typeInfo *GiInfo) string {
+	keyType := typeInfo.GetParamType(0) ; defer keyType.Free()
+	valueType := typeInfo.GetParamType(1) ; defer valueType.Free()
+	if !pointerStorable(keyType) || !pointerStorable(valueType) {
+		return ""
+	}
+	key, kp := GoType(keyType)
+	value, vp := GoType(valueType)
+	if key == "" || value == "" || strings.HasPrefix(kp, "[]") || strings.HasPrefix(key, "map[") {
+		// slices and maps can't be keys
+		return ""
+	}
+	return fmt.Sprintf("map[%s]%s", kp + key, vp + value)
+}
+
+// Copies a Go map into a new hash table, which is released after the call
+// unless the callee takes it. String keys are hashed by value and everything
+// else by pointer. Keys and values are marshaled the same way as single values.
+func marshalHashToC(arg Argument) (ctype string, marshal string) {
+	typeInfo := arg.typ
+	govar := noKeywords(arg.name)
+	cvar := arg.cname
+	if hashGoType(typeInfo) == "" {
+		return "", ""
+	}
+	keyType := typeInfo.GetParamType(0) ; defer keyType.Free()
+	valueType := typeInfo.GetParamType(1) ; defer valueType.Free()
+
+	keyArg := Argument{arg.info, keyType, In, govar + "_key", cvar + "_key", ""}
+	keyCType, keyMarshal := MarshalToC(keyArg)
+	valueArg := Argument{arg.info, valueType, In, govar + "_value", cvar + "_value", ""}
+	valueCType, valueMarshal := MarshalToC(valueArg)
+	if keyCType == "" || keyCType == "C." || valueCType == "" || valueCType == "C." {
+		return "", ""
+	}
+
+	strs := 0
+	switch keyType.GetTag() {
+		case Utf8Tag, FilenameTag:
+			strs = 1
+	}
+	ctype = "*C.GHashTable"
+	marshal += fmt.Sprintf("%s = C.gogi_hash_table_new(%d)\n", cvar, strs)
+	marshal += fmt.Sprintf("\tfor %s, %s := range %s {\n", keyArg.name, valueArg.name, govar)
+	marshal += fmt.Sprintf("\t\tvar %s %s\n", keyArg.cname, keyCType)
+	marshal += "\t\t" + indent(keyMarshal) + "\n"
+	marshal += fmt.Sprintf("\t\tvar %s %s\n", valueArg.cname, valueCType)
+	marshal += "\t\t" + indent(valueMarshal) + "\n"
+	marshal += fmt.Sprintf("\t\tC.g_hash_table_insert(%s, %s, %s)\n", cvar, toPointer(keyType, keyArg.cname), toPointer(valueType, valueArg.cname))
+	marshal += "\t}"
+	if arg.dir == In && getTransfer(arg) == Nothing {
+		marshal += fmt.Sprintf("\n\tdefer C.g_hash_table_unref(%s)", cvar)
+	}
+	return
+}
+
+// Copies a hash table into a Go map. It's released afterwards if it was
+// handed over; its keys and values are kept if they were handed over too,
+// and otherwise left to its destroy functions.
+func marshalHashToGo(arg Argument) (gotype string, marshal string) {
+	typeInfo := arg.typ
+	govar := arg.name
+	cvar := arg.cname
+	eq := ":="
+	if arg.dir == InOut {
+		eq = "="
+	}
+	if gotype = hashGoType(typeInfo); gotype == "" {
+		return "", ""
+	}
+	keyType := typeInfo.GetParamType(0) ; defer keyType.Free()
+	valueType := typeInfo.GetParamType(1) ; defer valueType.Free()
+
+	keyArg := Argument{arg.info, keyType, In, govar + "_key", cvar + "_key", ""}
+	_, keyMarshal := MarshalToGo(keyArg)
+	valueArg := Argument{arg.info, valueType, In, govar + "_value", cvar + "_value", ""}
+	_, valueMarshal := MarshalToGo(valueArg)
+
+	marshal += fmt.Sprintf("%s %s make(%s)\n", govar, eq, gotype)
+	marshal += fmt.Sprintf("\tif %s != nil {\n", cvar)
+	marshal += fmt.Sprintf("\t\tvar %s_iter C.GHashTableIter\n", cvar)
+	marshal += fmt.Sprintf("\t\tvar %s_k, %s_v C.gpointer\n", cvar, cvar)
+	marshal += fmt.Sprintf("\t\tC.g_hash_table_iter_init(&%s_iter, %s)\n", cvar, cvar)
+	marshal += fmt.Sprintf("\t\tfor C.g_hash_table_iter_next(&%s_iter, &%s_k, &%s_v) != 0 {\n", cvar, cvar, cvar)
+	marshal += fmt.Sprintf("\t\t\t%s := %s\n", keyArg.cname, fromPointer(keyType, cvar + "_k"))
+	marshal += "\t\t\t" + indent(indent(keyMarshal)) + "\n"
+	marshal += fmt.Sprintf("\t\t\t%s := %s\n", valueArg.cname, fromPointer(valueType, cvar + "_v"))
+	marshal += "\t\t\t" + indent(indent(valueMarshal)) + "\n"
+	marshal += fmt.Sprintf("\t\t\t%s[%s] = %s\n", govar, keyArg.name, valueArg.name)
+	marshal += "\t\t}\n"
+	switch getTransfer(arg) {
+		case Container:
+			marshal += fmt.Sprintf("\t\tC.g_hash_table_unref(%s)\n", cvar)
+		case Everything:
+			// the keys and values are ours now, so they mustn't be destroyed along with it
+			marshal += fmt.Sprintf("\t\tC.g_hash_table_steal_all(%s)\n", cvar)
+			marshal += fmt.Sprintf("\t\tC.g_hash_table_unref(%s)\n", cvar)
+	}
+	marshal += "\t}"
+	return
+}
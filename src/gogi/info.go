@@ -4,6 +4,47 @@ package gogi
 #include <glib.h>
 #include <glib-object.h>
 #include <girepository.h>
+
+GIArgument *constant_value(GIConstantInfo *info) {
+	GIArgument *value = g_new0(GIArgument, 1);
+	g_constant_info_get_value(info, value);
+	return value;
+}
+
+void constant_value_free(GIConstantInfo *info, GIArgument *value) {
+	g_constant_info_free_value(info, value);
+	g_free(value);
+}
+
+gint64 argument_int(GIArgument *value, GITypeTag tag) {
+	switch (tag) {
+		case GI_TYPE_TAG_INT8: return value->v_int8;
+		case GI_TYPE_TAG_INT16: return value->v_int16;
+		case GI_TYPE_TAG_INT32: return value->v_int32;
+		case GI_TYPE_TAG_INT64: return value->v_int64;
+		default: return 0;
+	}
+}
+
+guint64 argument_uint(GIArgument *value, GITypeTag tag) {
+	switch (tag) {
+		case GI_TYPE_TAG_UINT8: return value->v_uint8;
+		case GI_TYPE_TAG_UINT16: return value->v_uint16;
+		case GI_TYPE_TAG_UINT32: return value->v_uint32;
+		case GI_TYPE_TAG_UINT64: return value->v_uint64;
+		default: return 0;
+	}
+}
+
+gdouble argument_double(GIArgument *value, GITypeTag tag) {
+	if (tag == GI_TYPE_TAG_FLOAT) {
+		return value->v_float;
+	}
+	return value->v_double;
+}
+
+gboolean argument_boolean(GIArgument *value) { return value->v_boolean; }
+gchar *argument_string(GIArgument *value) { return value->v_string; }
 */
 import "C"
 import (
@@ -43,13 +84,19 @@ type GiInfo struct {
 	Type GiType
 }
 
+// returns nil for a NULL info, e.g. the parent of a root object
 func NewGiInfo(ptr *C.GIBaseInfo) *GiInfo {
+	if ptr == nil {
+		return nil
+	}
 	typ := (GiType)(C.g_base_info_get_type(ptr))
 	return &GiInfo{ptr, typ}
 }
 
 func (info *GiInfo) Free() {
-	C.g_base_info_unref(info.ptr)
+	if info != nil {
+		C.g_base_info_unref(info.ptr)
+	}
 }
 
 type TypeTag C.GITypeTag
@@ -94,6 +141,15 @@ func (info *GiInfo) GetNamespace() string {
 	return GoString(C.g_base_info_get_namespace(info.ptr))
 }
 
+// the callable an argument belongs to, for example
+func (info *GiInfo) GetContainer() *GiInfo {
+	container := C.g_base_info_get_container(info.ptr)
+	if container == nil {
+		return nil
+	}
+	return NewGiInfo(C.g_base_info_ref(container))
+}
+
 func (info *GiInfo) IsDeprecated() bool {
 	return GoBool(C.g_base_info_is_deprecated(info.ptr))
 }
@@ -306,6 +362,11 @@ func (info *GiInfo) GetEnumMethod(n int) *GiInfo {
 	return NewGiInfo((*C.GIBaseInfo)(C.g_enum_info_get_method((*C.GIEnumInfo)(info.ptr), GlibInt(n))))
 }
 
+// the quark string of the error domain an enum holds the codes of, or ""
+func (info *GiInfo) GetErrorDomain() string {
+	return GoString(C.g_enum_info_get_error_domain((*C.GIEnumInfo)(info.ptr)))
+}
+
 func (info *GiInfo) GetStorageType() TypeTag {
 	return (TypeTag)(C.g_enum_info_get_storage_type((*C.GIEnumInfo)(info.ptr)))
 }
@@ -341,6 +402,114 @@ func (info *GiInfo) IsForeign() bool {
 	return GoBool(C.g_struct_info_is_foreign((*C.GIStructInfo)(info.ptr)))
 }
 
+/* -- Constant Info -- */
+
+func (info *GiInfo) GetConstantType() *GiInfo {
+	return NewGiInfo((*C.GIBaseInfo)(C.g_constant_info_get_type((*C.GIConstantInfo)(info.ptr))))
+}
+
+// Gets the value as a bool, int64, uint64, float64 or string depending on
+// its type, or nil if it's none of those
+func (info *GiInfo) GetConstantValue() interface{} {
+	typ := info.GetConstantType() ; defer typ.Free()
+	tag := typ.GetTag()
+	value := C.constant_value((*C.GIConstantInfo)(info.ptr))
+	defer C.constant_value_free((*C.GIConstantInfo)(info.ptr), value)
+
+	switch tag {
+		case BooleanTag:
+			return GoBool(C.argument_boolean(value))
+		case Int8Tag, Int16Tag, Int32Tag, Int64Tag:
+			return (int64)(C.argument_int(value, (C.GITypeTag)(tag)))
+		case Uint8Tag, Uint16Tag, Uint32Tag, Uint64Tag:
+			return (uint64)(C.argument_uint(value, (C.GITypeTag)(tag)))
+		case FloatTag, DoubleTag:
+			return GoDouble(C.argument_double(value, (C.GITypeTag)(tag)))
+		case Utf8Tag, FilenameTag:
+			return GoString(C.argument_string(value))
+	}
+	return nil
+}
+
+/* -- Union Info -- */
+
+func (info *GiInfo) GetNUnionFields() int {
+	return GoInt(C.g_union_info_get_n_fields((*C.GIUnionInfo)(info.ptr)))
+}
+
+func (info *GiInfo) GetUnionField(n int) *GiInfo {
+	return NewGiInfo((*C.GIBaseInfo)(C.g_union_info_get_field((*C.GIUnionInfo)(info.ptr), GlibInt(n))))
+}
+
+func (info *GiInfo) GetNUnionMethods() int {
+	return GoInt(C.g_union_info_get_n_methods((*C.GIUnionInfo)(info.ptr)))
+}
+
+func (info *GiInfo) GetUnionMethod(n int) *GiInfo {
+	return NewGiInfo((*C.GIBaseInfo)(C.g_union_info_get_method((*C.GIUnionInfo)(info.ptr), GlibInt(n))))
+}
+
+func (info *GiInfo) IsDiscriminated() bool {
+	return GoBool(C.g_union_info_is_discriminated((*C.GIUnionInfo)(info.ptr)))
+}
+
+func (info *GiInfo) GetDiscriminatorOffset() int {
+	return GoInt(C.g_union_info_get_discriminator_offset((*C.GIUnionInfo)(info.ptr)))
+}
+
+func (info *GiInfo) GetDiscriminatorType() *GiInfo {
+	return NewGiInfo((*C.GIBaseInfo)(C.g_union_info_get_discriminator_type((*C.GIUnionInfo)(info.ptr))))
+}
+
+// the constant the discriminator has when field n is in use
+func (info *GiInfo) GetDiscriminator(n int) *GiInfo {
+	return NewGiInfo((*C.GIBaseInfo)(C.g_union_info_get_discriminator((*C.GIUnionInfo)(info.ptr), GlibInt(n))))
+}
+
+func (info *GiInfo) GetDiscriminatorValue(n int) int64 {
+	constant := info.GetDiscriminator(n) ; defer constant.Free()
+	switch value := constant.GetConstantValue().(type) {
+		case int64:
+			return value
+		case uint64:
+			return (int64)(value)
+	}
+	return 0
+}
+
+/* -- Field Info -- */
+
+type FieldFlags struct {
+	Readable bool
+	Writable bool
+}
+
+func NewFieldFlags(bits C.GIFieldInfoFlags) *FieldFlags {
+	var flags FieldFlags
+	PopulateFlags(&flags, (C.gint)(bits), []C.gint{
+		C.GI_FIELD_IS_READABLE,
+		C.GI_FIELD_IS_WRITABLE,
+	})
+	return &flags
+}
+
+func (info *GiInfo) GetFieldFlags() *FieldFlags {
+	return NewFieldFlags(C.g_field_info_get_flags((*C.GIFieldInfo)(info.ptr)))
+}
+
+// in bits; only set for bitfields
+func (info *GiInfo) GetFieldSize() int {
+	return GoInt(C.g_field_info_get_size((*C.GIFieldInfo)(info.ptr)))
+}
+
+func (info *GiInfo) GetFieldOffset() int {
+	return GoInt(C.g_field_info_get_offset((*C.GIFieldInfo)(info.ptr)))
+}
+
+func (info *GiInfo) GetFieldType() *GiInfo {
+	return NewGiInfo((*C.GIBaseInfo)(C.g_field_info_get_type((*C.GIFieldInfo)(info.ptr))))
+}
+
 /* -- Object Info -- */
 
 func (info *GiInfo) GetObjectTypeName() string {
@@ -363,6 +532,15 @@ func (info *GiInfo) GetParent() *GiInfo {
 	return NewGiInfo((*C.GIBaseInfo)(C.g_object_info_get_parent((*C.GIObjectInfo)(info.ptr))))
 }
 
+// only set for fundamental types that aren't GObjects
+func (info *GiInfo) GetRefFunction() string {
+	return C.GoString(C.g_object_info_get_ref_function((*C.GIObjectInfo)(info.ptr)))
+}
+
+func (info *GiInfo) GetUnrefFunction() string {
+	return C.GoString(C.g_object_info_get_unref_function((*C.GIObjectInfo)(info.ptr)))
+}
+
 func (info *GiInfo) GetNObjectInterfaces() int {
 	return GoInt(C.g_object_info_get_n_interfaces((*C.GIObjectInfo)(info.ptr)))
 }
@@ -419,6 +597,102 @@ func (info *GiInfo) GetConstant(n int) *GiInfo {
 	return NewGiInfo((*C.GIBaseInfo)(C.g_object_info_get_constant((*C.GIObjectInfo)(info.ptr), GlibInt(n))))
 }
 
+/* -- Interface Info -- */
+
+func (info *GiInfo) GetNPrerequisites() int {
+	return GoInt(C.g_interface_info_get_n_prerequisites((*C.GIInterfaceInfo)(info.ptr)))
+}
+
+func (info *GiInfo) GetPrerequisite(n int) *GiInfo {
+	return NewGiInfo((*C.GIBaseInfo)(C.g_interface_info_get_prerequisite((*C.GIInterfaceInfo)(info.ptr), GlibInt(n))))
+}
+
+func (info *GiInfo) GetNInterfaceProperties() int {
+	return GoInt(C.g_interface_info_get_n_properties((*C.GIInterfaceInfo)(info.ptr)))
+}
+
+func (info *GiInfo) GetInterfaceProperty(n int) *GiInfo {
+	return NewGiInfo((*C.GIBaseInfo)(C.g_interface_info_get_property((*C.GIInterfaceInfo)(info.ptr), GlibInt(n))))
+}
+
+func (info *GiInfo) GetNInterfaceMethods() int {
+	return GoInt(C.g_interface_info_get_n_methods((*C.GIInterfaceInfo)(info.ptr)))
+}
+
+func (info *GiInfo) GetInterfaceMethod(n int) *GiInfo {
+	return NewGiInfo((*C.GIBaseInfo)(C.g_interface_info_get_method((*C.GIInterfaceInfo)(info.ptr), GlibInt(n))))
+}
+
+func (info *GiInfo) GetNInterfaceSignals() int {
+	return GoInt(C.g_interface_info_get_n_signals((*C.GIInterfaceInfo)(info.ptr)))
+}
+
+func (info *GiInfo) GetInterfaceSignal(n int) *GiInfo {
+	return NewGiInfo((*C.GIBaseInfo)(C.g_interface_info_get_signal((*C.GIInterfaceInfo)(info.ptr), GlibInt(n))))
+}
+
+func (info *GiInfo) GetNInterfaceVFuncs() int {
+	return GoInt(C.g_interface_info_get_n_vfuncs((*C.GIInterfaceInfo)(info.ptr)))
+}
+
+func (info *GiInfo) GetInterfaceVFunc(n int) *GiInfo {
+	return NewGiInfo((*C.GIBaseInfo)(C.g_interface_info_get_vfunc((*C.GIInterfaceInfo)(info.ptr), GlibInt(n))))
+}
+
+func (info *GiInfo) GetNInterfaceConstants() int {
+	return GoInt(C.g_interface_info_get_n_constants((*C.GIInterfaceInfo)(info.ptr)))
+}
+
+func (info *GiInfo) GetInterfaceConstant(n int) *GiInfo {
+	return NewGiInfo((*C.GIBaseInfo)(C.g_interface_info_get_constant((*C.GIInterfaceInfo)(info.ptr), GlibInt(n))))
+}
+
+func (info *GiInfo) GetIfaceStruct() *GiInfo {
+	return NewGiInfo((*C.GIBaseInfo)(C.g_interface_info_get_iface_struct((*C.GIInterfaceInfo)(info.ptr))))
+}
+
+/* -- Property Info -- */
+
+type ParamFlags struct {
+	Readable bool
+	Writable bool
+	Construct bool
+	ConstructOnly bool
+	LaxValidation bool
+	StaticName bool
+	StaticNick bool
+	StaticBlurb bool
+	Deprecated bool
+}
+
+func NewParamFlags(bits C.GParamFlags) *ParamFlags {
+	var flags ParamFlags
+	PopulateFlags(&flags, (C.gint)(bits), []C.gint{
+		C.G_PARAM_READABLE,
+		C.G_PARAM_WRITABLE,
+		C.G_PARAM_CONSTRUCT,
+		C.G_PARAM_CONSTRUCT_ONLY,
+		C.G_PARAM_LAX_VALIDATION,
+		C.G_PARAM_STATIC_NAME,
+		C.G_PARAM_STATIC_NICK,
+		C.G_PARAM_STATIC_BLURB,
+		C.G_PARAM_DEPRECATED,
+	})
+	return &flags
+}
+
+func (info *GiInfo) GetPropertyFlags() *ParamFlags {
+	return NewParamFlags(C.g_property_info_get_flags((*C.GIPropertyInfo)(info.ptr)))
+}
+
+func (info *GiInfo) GetPropertyType() *GiInfo {
+	return NewGiInfo((*C.GIBaseInfo)(C.g_property_info_get_type((*C.GIPropertyInfo)(info.ptr))))
+}
+
+func (info *GiInfo) GetPropertyOwnershipTransfer() Transfer {
+	return (Transfer)(C.g_property_info_get_ownership_transfer((*C.GIPropertyInfo)(info.ptr)))
+}
+
 /* -- Arg Info -- */
 
 type Direction C.GIDirection
@@ -464,7 +738,15 @@ func (info *GiInfo) GetScope() ScopeType {
 	return (ScopeType)(C.g_arg_info_get_scope((*C.GIArgInfo)(info.ptr)))
 }
 
-// TODO: get closure/destroy?
+// for callback arguments, the index of the user data argument; -1 if none
+func (info *GiInfo) GetClosure() int {
+	return GoInt(C.g_arg_info_get_closure((*C.GIArgInfo)(info.ptr)))
+}
+
+// for callback arguments, the index of the destroy notify argument; -1 if none
+func (info *GiInfo) GetDestroy() int {
+	return GoInt(C.g_arg_info_get_destroy((*C.GIArgInfo)(info.ptr)))
+}
 
 func (info *GiInfo) GetType() *GiInfo {
 	return NewGiInfo((*C.GIBaseInfo)(C.g_arg_info_get_type((*C.GIArgInfo)(info.ptr))))
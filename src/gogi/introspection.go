@@ -26,9 +26,14 @@ GList *get_dependencies(const gchar *namespace) {
 	GList *results = NULL;
 	gchar **dependencies = g_irepository_get_dependencies(NULL, namespace);
 	gint i = 0;
+	// namespaces without any, like GLib, may not have an array at all
+	if (dependencies == NULL) {
+		return NULL;
+	}
 	while (dependencies[i] != NULL) {
 		results = g_list_prepend(results, dependencies[i++]);
 	}
+	g_free(dependencies);
 	return g_list_reverse(results);
 }
 
@@ -53,27 +58,40 @@ import (
 	//"fmt"
 	//"reflect"
 	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
 	"strings"
 	"path/filepath"
 )
 
 var cExports map[string] bool
+var goFuncs map[string] bool
 var cNamespace string
 var prefixes map[string]string
 var blacklist map[string] bool
+var imports map[string] bool
 
 func LoadNamespace(namespace string) bool {
 	_namespace := GlibString(namespace) ; defer C.g_free((C.gpointer)(_namespace))
 	success := GoBool(C.load_namespace(_namespace))
 	if success {
 		cExports = make(map[string]bool)
+		goFuncs = make(map[string]bool)
 		cNamespace = namespace
+		goExports = ""
+		imports = make(map[string]bool)
 
 		prefixes = make(map[string]string)
+		reserved = make(map[string]map[string]bool)
+		methods = make(map[string][]string)
 		blacklist = make(map[string]bool)
 		content, err := ioutil.ReadFile(filepath.Join("blacklist", namespace))
 		if err != nil {
-			println("error reading blacklist:", err.Error())
+			// most namespaces don't need one
+			if !os.IsNotExist(err) {
+				println("error reading blacklist:", err.Error())
+			}
 		} else {
 			lines := strings.Split(string(content), "\n")
 			for _, line := range lines {
@@ -89,6 +107,37 @@ func LoadNamespace(namespace string) bool {
 	return success
 }
 
+// the standard packages generated code may use
+var stdImports = []string{"fmt", "reflect", "runtime", "sync", "unsafe"}
+
+// Gets the packages the generated code uses; code referring to other
+// namespaces is sometimes dropped, so only those it actually uses are kept
+func GetImports(code string) []string {
+	results := make([]string, 0)
+	candidates := append([]string{}, stdImports...)
+	for path := range imports {
+		candidates = append(candidates, path)
+	}
+	for _, path := range candidates {
+		name := path[strings.LastIndex(path, "/") + 1:]
+		used := regexp.MustCompile(`(^|[^\w.])` + name + `\.`)
+		if used.MatchString(code) {
+			results = append(results, path)
+		}
+	}
+	sort.Strings(results)
+	return results
+}
+
+// Gets the Go package a namespace is generated into
+func PackageName(namespace string) string {
+	return strings.ToLower(namespace)
+}
+
+func PackagePath(namespace string) string {
+	return "gi/" + PackageName(namespace)
+}
+
 func GetNamespaces() *list.List {
 	raw_list := GListToGo(C.get_namespaces())
 	namespaces := list.New()
@@ -98,19 +147,46 @@ func GetNamespaces() *list.List {
 	return namespaces
 }
 
+// Gets the namespaces a loaded namespace depends on, without their versions
 func GetDependencies(namespace string) []string {
-	if namespace == "GLib" {
-		return make([]string, 0)
-	}
 	_namespace := GlibString(namespace) ; defer C.g_free((C.gpointer)(_namespace))
 	raw_list := GListToGo(C.get_dependencies(_namespace))
 	results := make([]string, raw_list.Len())
 	for i, e := 0, raw_list.Front(); e != nil; i, e = i + 1, e.Next() {
-		results[i] = C.GoString((*C.char)(e.Value.(C.gpointer)))
+		dep := (*C.gchar)(e.Value.(C.gpointer))
+		// they look like "GObject-2.0"
+		results[i] = strings.SplitN(GoString(dep), "-", 2)[0]
+		C.g_free((C.gpointer)(dep))
 	}
 	return results
 }
 
+// Sorts a loaded namespace and everything it depends on, directly or not, so
+// that each one comes after its dependencies; the namespace itself is last
+func GetDependencyOrder(namespace string) []string {
+	results := make([]string, 0)
+	visited := make(map[string]bool)
+	var visit func(string)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		for _, dep := range GetDependencies(name) {
+			visit(dep)
+		}
+		results = append(results, name)
+	}
+	visit(namespace)
+	return results
+}
+
+// Gets the file a loaded namespace's typelib was read from
+func GetTypelibPath(namespace string) string {
+	_namespace := GlibString(namespace) ; defer C.g_free((C.gpointer)(_namespace))
+	return GoString(C.g_irepository_get_typelib_path(nil, _namespace))
+}
+
 func GetInfos(namespace string) []*GiInfo {
 	_namespace := GlibString(namespace) ; defer C.g_free((C.gpointer)(_namespace))
 	raw_list := GListToGo(C.get_infos(_namespace))
@@ -0,0 +1,81 @@
+package gogi
+
+import (
+	"fmt"
+)
+
+// Gets the C type and function prefix for a GList or GSList
+func listKind(typeInfo *GiInfo) (ctype string, prefix string) {
+	if typeInfo.GetTag() == GSListTag {
+		return "GSList", "g_slist"
+	}
+	return "GList", "g_list"
+}
+
+// Builds a new list from a Go slice. The links are freed after the call unless
+// the callee takes the list, and its elements are marshaled the same way as
+// single values, so they're freed unless the callee takes those too.
+func marshalListToC(arg Argument) (ctype string, marshal string) {
+	typeInfo := arg.typ
+	govar := noKeywords(arg.name)
+	cvar := arg.cname
+	elemType := typeInfo.GetParamType(0) ; defer elemType.Free()
+	if !pointerStorable(elemType) {
+		return "", ""
+	}
+
+	elemArg := Argument{arg.info, elemType, In, govar + "_el", cvar + "_el", ""}
+	elemCType, elemMarshal := MarshalToC(elemArg)
+	if elemCType == "" || elemCType == "C." {
+		return "", ""
+	}
+
+	list, prefix := listKind(typeInfo)
+	ctype = "*C." + list
+	// built back to front, since prepending is cheap
+	marshal += fmt.Sprintf("for i := len(%s) - 1; i >= 0; i-- {\n", govar)
+	marshal += fmt.Sprintf("\t\t%s := %s[i]\n", elemArg.name, govar)
+	marshal += fmt.Sprintf("\t\tvar %s %s\n", elemArg.cname, elemCType)
+	marshal += "\t\t" + indent(elemMarshal) + "\n"
+	marshal += fmt.Sprintf("\t\t%s = C.%s_prepend(%s, %s)\n", cvar, prefix, cvar, toPointer(elemType, elemArg.cname))
+	marshal += "\t}"
+	if arg.dir == In && getTransfer(arg) == Nothing {
+		marshal += fmt.Sprintf("\n\tdefer C.%s_free(%s)", prefix, cvar)
+	}
+	return
+}
+
+// Copies a GList or GSList into a Go slice. The links are freed afterwards if
+// the list was handed over, and its elements are kept if they were too.
+func marshalListToGo(arg Argument) (gotype string, marshal string) {
+	typeInfo := arg.typ
+	govar := arg.name
+	cvar := arg.cname
+	eq := ":="
+	if arg.dir == InOut {
+		eq = "="
+	}
+	elemType := typeInfo.GetParamType(0) ; defer elemType.Free()
+	if !pointerStorable(elemType) {
+		return "", ""
+	}
+
+	elemArg := Argument{arg.info, elemType, In, govar + "_el", cvar + "_el", ""}
+	elemGoType, elemMarshal := MarshalToGo(elemArg)
+	if elemGoType == "" {
+		return "", ""
+	}
+	gotype = "[]" + elemGoType
+
+	_, prefix := listKind(typeInfo)
+	marshal += fmt.Sprintf("%s %s make(%s, 0, (int)(C.%s_length(%s)))\n", govar, eq, gotype, prefix, cvar)
+	marshal += fmt.Sprintf("\tfor l := %s; l != nil; l = l.next {\n", cvar)
+	marshal += fmt.Sprintf("\t\t%s := %s\n", elemArg.cname, fromPointer(elemType, "l.data"))
+	marshal += "\t\t" + indent(elemMarshal) + "\n"
+	marshal += fmt.Sprintf("\t\t%s = append(%s, %s)\n", govar, govar, elemArg.name)
+	marshal += "\t}"
+	if getTransfer(arg) != Nothing {
+		marshal += fmt.Sprintf("\n\tC.%s_free(%s)", prefix, cvar)
+	}
+	return
+}
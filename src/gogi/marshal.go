@@ -20,7 +20,6 @@ import (
 	"container/list"
 	"fmt"
 	"reflect"
-	"strings"
 )
 
 var goTypes = map[int]string {
@@ -40,9 +39,6 @@ var goTypes = map[int]string {
 	(int)(C.GI_TYPE_TAG_UTF8):     "string",
 	(int)(C.GI_TYPE_TAG_FILENAME): "string",
 	// skip a couple
-	(int)(C.GI_TYPE_TAG_GLIST):    "list.List",
-	(int)(C.GI_TYPE_TAG_GSLIST):   "list.List",
-	// skip a couple
 	//(int)(C.GI_TYPE_TAG_UNICHAR):  "rune",
 }
 
@@ -65,10 +61,195 @@ var cTypes = map[int]string {
 	// skip a couple
 	(int)(C.GI_TYPE_TAG_GLIST):    "GList",
 	(int)(C.GI_TYPE_TAG_GSLIST):   "GSList",
+	(int)(C.GI_TYPE_TAG_GHASH):    "GHashTable",
 	// skip a couple
 	//(int)(C.GI_TYPE_TAG_UNICHAR):  "gunichar",
 }
 
+// Gets who owns a value once it has been passed across. Values with no info
+// are the ones returned to C from Go funcs, which C is left holding on to.
+func getTransfer(arg Argument) Transfer {
+	if arg.info == nil {
+		return Everything
+	}
+	switch arg.info.Type {
+		case Arg:
+			return arg.info.GetOwnershipTransfer()
+		case Function, Callback, Signal, VFunc:
+			return arg.info.GetCallerOwns()
+	}
+	// property values come out of a GValue, which keeps them; fields keep theirs too
+	return Nothing
+}
+
+// Gets the functions that take and release a reference on an instance of an
+// object type, along with the C type they take, which is "" for a gpointer
+func refFuncs(info *GiInfo) (ref string, unref string, ctype string) {
+	if info.Type != Object {
+		return "g_object_ref", "g_object_unref", ""
+	}
+	if ref = info.GetRefFunction(); ref != "" {
+		return ref, info.GetUnrefFunction(), GetPrefix(info) + info.GetName()
+	}
+	parent := info.GetParent() ; defer parent.Free()
+	if parent == nil {
+		return "g_object_ref", "g_object_unref", ""
+	}
+	return refFuncs(parent)
+}
+
+// Gets a call to one of the functions from refFuncs
+func refCall(fn, ctype, ptr string) string {
+	if ctype == "" {
+		return fmt.Sprintf("C.%s((C.gpointer)(%s))", fn, ptr)
+	}
+	return fmt.Sprintf("C.%s((*C.%s)((C.gpointer)(%s)))", fn, ctype, ptr)
+}
+
+// structs and unions with a registered GType can be copied and freed with g_boxed_*
+func isBoxed(info *GiInfo) bool {
+	if info.Type != Struct && info.Type != Union {
+		return false
+	}
+	init := info.GetRegisteredTypeInit()
+	return init != "" && init != "intern"
+}
+
+// Checks that a type can be stored in a container that holds pointers, like a
+// hash table or list, either as a pointer or as an integer small enough to fit in one
+func pointerStorable(typeInfo *GiInfo) bool {
+	if _, p := CType(typeInfo); p != "" {
+		return typeInfo.GetTag() != ArrayTag
+	}
+	switch typeInfo.GetTag() {
+		case BooleanTag, Int8Tag, Uint8Tag, Int16Tag, Uint16Tag, Int32Tag, Uint32Tag, UnicharTag:
+			return true
+		case InterfaceTag:
+			interfaceInfo := typeInfo.GetTypeInterface() ; defer interfaceInfo.Free()
+			return interfaceInfo.Type == Enum || interfaceInfo.Type == Flags
+	}
+	return false
+}
+
+// Gets an expression for a value stored in a pointer as its C type
+func fromPointer(typeInfo *GiInfo, expr string) string {
+	ctype, p := CType(typeInfo)
+	if p != "" {
+		return fmt.Sprintf("(%sC.%s)(%s)", p, ctype, expr)
+	}
+	return fmt.Sprintf("(C.%s)(C.gogi_pointer_int(%s))", ctype, expr)
+}
+
+// Gets an expression for a C value as it's stored in a pointer
+func toPointer(typeInfo *GiInfo, expr string) string {
+	if _, p := CType(typeInfo); p != "" {
+		return fmt.Sprintf("(C.gpointer)(%s)", expr)
+	}
+	return fmt.Sprintf("C.gogi_int_pointer((C.gint)(%s))", expr)
+}
+
+// Checks whether the Go type for a type can be nil; strings can't, so
+// nullable ones are passed in as *string and returned with an extra bool
+func isNilable(typeInfo *GiInfo) bool {
+	switch typeInfo.GetTag() {
+		case ArrayTag, GListTag, GSListTag, GHashTag:
+			return true
+		case VoidTag:
+			return typeInfo.IsPointer()
+		case InterfaceTag:
+			interfaceInfo := typeInfo.GetTypeInterface() ; defer interfaceInfo.Free()
+			switch interfaceInfo.Type {
+				case Object, Interface, Struct, Union, Callback:
+					return true
+			}
+	}
+	return false
+}
+
+// Checks whether a string argument or return value may be NULL
+func isNullableString(info *GiInfo, typeInfo *GiInfo) bool {
+	switch typeInfo.GetTag() {
+		case Utf8Tag, FilenameTag:
+		default:
+			return false
+	}
+	if info == nil {
+		return false
+	}
+	switch info.Type {
+		case Arg:
+			return info.MayBeNull()
+		case Function, Callback, Signal, VFunc:
+			return info.MayReturnNull()
+	}
+	return false
+}
+
+// Checks whether a type is a pointer to a GValue, which Go code passes around
+// as the Go value it holds
+func isValue(typeInfo *GiInfo) bool {
+	if typeInfo.GetTag() != InterfaceTag || !typeInfo.IsPointer() {
+		return false
+	}
+	interfaceInfo := typeInfo.GetTypeInterface() ; defer interfaceInfo.Free()
+	return interfaceInfo.GetNamespace() == "GObject" && interfaceInfo.GetName() == "Value"
+}
+
+// Checks whether a type is a pointer to a GVariant, which Go code outside of
+// GLib passes around as the Go value it holds
+func isVariant(typeInfo *GiInfo) bool {
+	if typeInfo.GetTag() != InterfaceTag || !typeInfo.IsPointer() || cNamespace == "GLib" {
+		return false
+	}
+	interfaceInfo := typeInfo.GetTypeInterface() ; defer interfaceInfo.Free()
+	return interfaceInfo.GetNamespace() == "GLib" && interfaceInfo.GetName() == "Variant"
+}
+
+// Checks whether a type is from a namespace other than the one being generated
+func isImported(info *GiInfo) bool {
+	return info.GetNamespace() != cNamespace
+}
+
+// Gets the Go name for a type, qualified with its package if it's from
+// another namespace, or "" if it can't be used from here
+func goTypeName(info *GiInfo) string {
+	if !isImported(info) {
+		return info.GetName()
+	}
+	switch info.Type {
+		case Callback:
+			// its package leaves out the ones it can't marshal
+			if callbackSignature(info) == "" {
+				return ""
+			}
+		case Struct:
+			if info.IsGTypeStruct() || info.IsForeign() {
+				return ""
+			}
+	}
+	imports[PackagePath(info.GetNamespace())] = true
+	return PackageName(info.GetNamespace()) + "." + info.GetName()
+}
+
+// Gets the function that wraps a pointer to an object, interface or boxed type;
+// types from other packages are wrapped through their exported functions
+func wrapFunc(info *GiInfo) string {
+	if !isImported(info) {
+		return "wrap" + info.GetName()
+	}
+	return PackageName(info.GetNamespace()) + ".Wrap" + info.GetName()
+}
+
+// Refers to one of the functions that only one namespace's package has, such
+// as GObject's subclass registry, from the package being generated
+func runtimeFunc(namespace string, name string) string {
+	if cNamespace == namespace {
+		return name
+	}
+	imports[PackagePath(namespace)] = true
+	return PackageName(namespace) + "." + name
+}
+
 // returns the C type and the necessary marshaling code
 func MarshalToC(arg Argument) (ctype string, marshal string) {
 	typeInfo := arg.typ
@@ -76,20 +257,7 @@ func MarshalToC(arg Argument) (ctype string, marshal string) {
 	govar := noKeywords(arg.name)
 	tag := typeInfo.GetTag()
 	if tag == ArrayTag {
-		switch typeInfo.GetArrayType() {
-			case C.GI_ARRAY_TYPE_C:
-				arg.name = govar + "_ar"
-				ar_ctype, _ := MarshalToC(Argument{typ:typeInfo.GetParamType(0), cname:cvar + "_ar", name:arg.name, dir:arg.dir})
-				ctype = "*" + ar_ctype
-				cvar_len := cvar + "_len"
-				cvar_val := cvar + "_val"
-				marshal = cvar_len + " := len(" + govar + ")\n\t" +
-				          cvar_val + " := make([]" + ar_ctype + ", " + cvar_len + ")\n\t" +
-				          "for i := 0; i < " + cvar_len + "; i++ {\n\t" +
-						  "\t" + cvar_val + "[i] = (*C.gchar)(C.CString((" + govar + ")[i]))\n\t" +
-						  "}\n\t" +
-						  cvar + " = (" + ar_ctype + ")(unsafe.Pointer(&" + cvar_val + "))"
-		}
+		return marshalArrayToC(arg)
 	} else {
 		var p string
 		ctype, p = CType(typeInfo)
@@ -123,23 +291,48 @@ func MarshalToC(arg Argument) (ctype string, marshal string) {
 				marshal = fmt.Sprintf("%s = (%s)(%s)", cvar, ctype, govar)
 			case C.GI_TYPE_TAG_UTF8, C.GI_TYPE_TAG_FILENAME:
 				marshal = fmt.Sprintf("%s = (%s)(C.CString(%s))", cvar, ctype, govar)
+				if arg.dir == In && getTransfer(arg) != Everything && arg.info.Type != Field {
+					// only borrowed for the call, so it's ours to free
+					marshal += fmt.Sprintf("\n\tdefer C.g_free((C.gpointer)(%s))", cvar)
+				}
 			case C.GI_TYPE_TAG_INTERFACE:
 				interfaceInfo := typeInfo.GetTypeInterface()
 				switch interfaceInfo.Type {
 					case Enum, Flags:
 						ctype = "C." + GetPrefix(interfaceInfo) + interfaceInfo.GetName()
 						marshal = fmt.Sprintf("%s = (%s)(%s)", cvar, ctype, govar)
-					case Object:
+					case Object, Interface:
 						marshal = fmt.Sprintf("%s = (%s).As%s()", cvar, govar, interfaceInfo.GetName())
-					case Struct:
+						if isImported(interfaceInfo) {
+							// C types from other packages are distinct Go types
+							marshal = fmt.Sprintf("%s = (%s)((C.gpointer)((%s).As%s()))", cvar, ctype, govar, interfaceInfo.GetName())
+						}
+						if getTransfer(arg) == Everything && arg.info != nil {
+							// the callee takes a reference, so give it one of its own
+							ref, _, reftype := refFuncs(interfaceInfo)
+							marshal += "\n\t" + refCall(ref, reftype, cvar)
+						}
+					case Struct, Union:
+						if isValue(typeInfo) {
+							marshal = marshalValueToC(arg, ctype)
+							break
+						}
+						if isVariant(typeInfo) {
+							marshal = marshalVariantToC(arg, ctype)
+							break
+						}
 						marshal = fmt.Sprintf("%s = (%s).ptr", cvar, govar)
+						if isImported(interfaceInfo) {
+							marshal = fmt.Sprintf("%s = (%s)((%s).Native())", cvar, ctype, govar)
+						}
+					case Callback:
+						// the Go func itself travels through the user data argument
+						marshal = fmt.Sprintf("%s = (%s)(C.gogi_%s)", cvar, ctype, callbackTrampolineName(interfaceInfo))
 				}
-			case C.GI_TYPE_TAG_GLIST:
-				ctype = "C.GList"
-				marshal = "// TODO: marshal glist"
-			case C.GI_TYPE_TAG_GSLIST:
-				ctype = "C.GSList"
-				marshal = "// TODO: marshal gslist"
+			case C.GI_TYPE_TAG_GHASH:
+				return marshalHashToC(arg)
+			case C.GI_TYPE_TAG_GLIST, C.GI_TYPE_TAG_GSLIST:
+				return marshalListToC(arg)
 			default:
 				ctype = "<CAN'T MARSHAL TO C: " + TypeTagToString(tag) + ">"
 				//ctype = "gint"
@@ -158,17 +351,7 @@ func MarshalToGo(arg Argument) (gotype string, marshal string) {
 		eq = "="
 	}
 	if tag == ArrayTag {
-		var ptr string
-		arrayType := typeInfo.GetParamType(0)
-		gotype, ptr = GoType(arrayType)
-		gotype = "[]" + ptr + gotype
-		marshal = "// TODO: marshal"
-		switch typeInfo.GetArrayType() {
-			case C.GI_ARRAY_TYPE_C:
-			default:
-				// TODO: implement other array types
-				return "", ""
-		}
+		return marshalArrayToGo(arg)
 	} else {
 		var ptr string
 		if typeInfo.IsPointer() {
@@ -181,7 +364,7 @@ func MarshalToGo(arg Argument) (gotype string, marshal string) {
 		switch tag {
 			case C.GI_TYPE_TAG_VOID:
 				if ptr != "" {
-					marshal = fmt.Sprintf("%s = reflect.ValueOf(%s).Interface()", govar, cvar)
+					marshal = fmt.Sprintf("%s %s reflect.ValueOf(%s).Interface()", govar, eq, cvar)
 				}
 			case C.GI_TYPE_TAG_BOOLEAN:
 				marshal = fmt.Sprintf("%s %s %s != 0", govar, eq, cvar)
@@ -201,32 +384,74 @@ func MarshalToGo(arg Argument) (gotype string, marshal string) {
 			case C.GI_TYPE_TAG_UTF8, C.GI_TYPE_TAG_FILENAME:
 				gotype = "string"
 				marshal = fmt.Sprintf("%s %s C.GoString((*C.char)(%s))", govar, eq, cvar)
+				if getTransfer(arg) == Everything {
+					marshal += fmt.Sprintf("\n\tC.g_free((C.gpointer)(%s))", cvar)
+				}
 			case C.GI_TYPE_TAG_INTERFACE:
 				interfaceInfo := typeInfo.GetTypeInterface()
-				name := interfaceInfo.GetName()
+				name := goTypeName(interfaceInfo)
+				if name == "" {
+					return "", ""
+				}
+				wrap := wrapFunc(interfaceInfo)
 				switch interfaceInfo.Type {
-					case Object:
+					case Enum, Flags:
+						gotype = name
+						marshal = fmt.Sprintf("%s %s %s(%s)", govar, eq, gotype, cvar)
+					case Object, Interface:
 						//gotype = ptr + name
 						gotype = name
-						marshal = fmt.Sprintf("%s %s &%s{C.as_%s((C.gpointer)(%s))}", govar, eq, GetImplName(name), strings.ToLower(gotype), cvar)
-					case Struct:
+						// the wrapper holds a reference of its own, taking one if it wasn't handed over
+						owned := getTransfer(arg) == Everything
+						if isImported(interfaceInfo) {
+							marshal = fmt.Sprintf("%s %s %s(unsafe.Pointer(%s), %t)", govar, eq, wrap, cvar, owned)
+							break
+						}
+						marshal = fmt.Sprintf("%s %s %s(C.%s((C.gpointer)(%s)), %t)", govar, eq, wrap, castName(GetPrefix(interfaceInfo), gotype), cvar, owned)
+					case Struct, Union:
+						if isValue(typeInfo) {
+							return marshalValueToGo(arg)
+						}
+						if isVariant(typeInfo) {
+							return marshalVariantToGo(arg)
+						}
 						//gotype = ptr + name
 						gotype = "*" + name
-						var addr string
+						if isImported(interfaceInfo) {
+							addr := "&"
+							if ptr != "" {
+								addr = ""
+							}
+							if isBoxed(interfaceInfo) {
+								// one that's in Go memory always needs copying out
+								copy := getTransfer(arg) != Everything || ptr == ""
+								marshal = fmt.Sprintf("%s %s %s(unsafe.Pointer(%s%s), %t)", govar, eq, wrap, addr, cvar, copy)
+							} else {
+								marshal = fmt.Sprintf("%s %s %s(unsafe.Pointer(%s%s))", govar, eq, wrap, addr, cvar)
+							}
+							break
+						}
+						if ptr != "" && isBoxed(interfaceInfo) {
+							// borrowed ones are copied, owned ones are freed along with the Go value
+							marshal = fmt.Sprintf("%s %s %s(%s, %t)", govar, eq, wrap, cvar, getTransfer(arg) != Everything)
+							break
+						}
 						if ptr == "" {
-							addr = "&"
+							marshal = fmt.Sprintf("%s %s &%s{&%s}", govar, eq, name, cvar)
+							break
 						}
-						marshal = fmt.Sprintf("%s %s &%s{%s}", govar, eq, name, addr + cvar)
+						// so that NULL comes back as nil
+						marshal = fmt.Sprintf("%s %s (*%s)(nil)\n", govar, eq, name) +
+						          fmt.Sprintf("\tif %s != nil {\n", cvar) +
+						          fmt.Sprintf("\t\t%s = &%s{%s}\n", govar, name, cvar) +
+						          "\t}"
 					default:
 						marshal = fmt.Sprintf("// TODO: marshal %d", interfaceInfo.Type)
 				}
+			case C.GI_TYPE_TAG_GHASH:
+				return marshalHashToGo(arg)
 			case C.GI_TYPE_TAG_GLIST, C.GI_TYPE_TAG_GSLIST:
-				gotype = "*list.List"
-				marshal = fmt.Sprintf("%s %s list.New()\n", govar, eq) +
-				          fmt.Sprintf("\tfor %s != nil {\n", cvar) +
-					  fmt.Sprintf("\t\t%s.PushBack(%s.data)\n", govar, cvar) +
-					  fmt.Sprintf("\t\t%s = %s.next\n", cvar, cvar) +
-					  fmt.Sprintf("\t}\n")
+				return marshalListToGo(arg)
 			default:
 				gotype = "<CAN'T MARSHAL TO GO: " + TypeTagToString(tag) + ">"
 		}
@@ -234,6 +459,73 @@ func MarshalToGo(arg Argument) (gotype string, marshal string) {
 	return
 }
 
+// Puts a Go value into a GValue; *Values are passed as they are, and kept
+// alive until the call returns, or until they're copied if C keeps them.
+func marshalValueToC(arg Argument, ctype string) (marshal string) {
+	govar := noKeywords(arg.name)
+	cvar := arg.cname
+	marshal += fmt.Sprintf("%s_value := %s(%s)\n", cvar, runtimeFunc("GObject", "ToValue"), govar)
+	if getTransfer(arg) == Everything {
+		marshal += fmt.Sprintf("\t%s = (%s)(C.g_boxed_copy(C.g_value_get_type(), (C.gconstpointer)(%s_value.Native())))\n", cvar, ctype, cvar)
+	} else {
+		marshal += fmt.Sprintf("\t%s = (%s)(%s_value.Native())\n", cvar, ctype, cvar)
+	}
+	marshal += fmt.Sprintf("\tdefer runtime.KeepAlive(%s_value)", cvar)
+	return
+}
+
+// Gets the Go value out of a GValue. Borrowed ones are copied first, and ones
+// the caller allocated are unset afterwards, since they're on the stack.
+func marshalValueToGo(arg Argument) (gotype string, marshal string) {
+	govar := arg.name
+	cvar := arg.cname
+	eq := ":="
+	if arg.dir == InOut {
+		eq = "="
+	}
+	copy := getTransfer(arg) != Everything
+	callerAllocates := arg.dir == Out && arg.info != nil && arg.info.Type == Arg && arg.info.IsCallerAllocates()
+	if callerAllocates {
+		cvar = "&" + cvar
+		copy = true
+	}
+	if cNamespace == "GObject" {
+		marshal = fmt.Sprintf("%s %s wrapValue(%s, %t).Go()", govar, eq, cvar, copy)
+	} else {
+		marshal = fmt.Sprintf("%s %s %s(unsafe.Pointer(%s), %t).Go()", govar, eq, runtimeFunc("GObject", "WrapValue"), cvar, copy)
+	}
+	if callerAllocates {
+		marshal += fmt.Sprintf("\n\tC.g_value_unset(%s)", cvar)
+	}
+	return "interface{}", marshal
+}
+
+// Makes a GVariant from a Go value, with the type glib.VariantTypeOf gives it;
+// *glib.Variants are passed as they are. Either way it's kept alive until the
+// call returns, and C gets a reference of its own if it takes one.
+func marshalVariantToC(arg Argument, ctype string) (marshal string) {
+	govar := noKeywords(arg.name)
+	cvar := arg.cname
+	marshal += fmt.Sprintf("%s_variant := %s(%s)\n", cvar, runtimeFunc("GLib", "ToVariant"), govar)
+	marshal += fmt.Sprintf("\t%s = (%s)(%s_variant.Native())\n", cvar, ctype, cvar)
+	if getTransfer(arg) == Everything {
+		marshal += fmt.Sprintf("\tC.g_variant_ref(%s)\n", cvar)
+	}
+	marshal += fmt.Sprintf("\tdefer runtime.KeepAlive(%s_variant)", cvar)
+	return
+}
+
+// Gets the Go value out of a GVariant, going by its type string
+func marshalVariantToGo(arg Argument) (gotype string, marshal string) {
+	eq := ":="
+	if arg.dir == InOut {
+		eq = "="
+	}
+	owned := getTransfer(arg) == Everything
+	marshal = fmt.Sprintf("%s %s %s(unsafe.Pointer(%s), %t).Go()", arg.name, eq, runtimeFunc("GLib", "WrapVariant"), arg.cname, owned)
+	return "interface{}", marshal
+}
+
 func GoType(typeInfo *GiInfo) (string, string) {
 	var ptr string
 	if typeInfo.IsPointer() {
@@ -259,24 +551,40 @@ func GoType(typeInfo *GiInfo) (string, string) {
 		// check non-primitive tags
 		// TODO: find callbacks
 		switch tag {
+			case C.GI_TYPE_TAG_GHASH:
+				// maps are already reference types
+				return hashGoType(typeInfo), ""
+			case C.GI_TYPE_TAG_GLIST, C.GI_TYPE_TAG_GSLIST:
+				elemType := typeInfo.GetParamType(0) ; defer elemType.Free()
+				if !pointerStorable(elemType) {
+					return "", ""
+				}
+				gotype, p := GoType(elemType)
+				return gotype, "[]" + p
 			case C.GI_TYPE_TAG_INTERFACE:
+				if isValue(typeInfo) || isVariant(typeInfo) {
+					return "interface{}", ""
+				}
 				interfaceType := typeInfo.GetTypeInterface()
-				// for now, ignore types not in this namespace
-				if interfaceType.GetNamespace() != cNamespace {
+				name := goTypeName(interfaceType)
+				if name == "" {
 					return "", ""
 				}
 
 				if interfaceType.Type == Callback {
-					// TODO: enable callbacks
-					return "", ""
-				} else if interfaceType.Type == Object {
+					// callbacks are Go funcs, which are already reference types
+					if interfaceType.IsDeprecated() || callbackSignature(interfaceType) == "" {
+						return "", ""
+					}
+					return name, ""
+				} else if interfaceType.Type == Object || interfaceType.Type == Interface {
 					// objects are interfaces, so don't include pointers
-					return interfaceType.GetName(), ""
-				} else if interfaceType.Type == Struct {
+					return name, ""
+				} else if interfaceType.Type == Struct || interfaceType.Type == Union {
 					// always pass structs around as pointers
-					return interfaceType.GetName(), "*"
+					return name, "*"
 				} else {
-					return interfaceType.GetName(), ptr
+					return name, ptr
 				}
 		}
 	}
@@ -292,7 +600,9 @@ func CType(typeInfo *GiInfo) (string, string) {
 	}
 	tag := typeInfo.GetTag()
 	if tag == ArrayTag {
-		// TODO: re-enable useful array functions
+		if kind, ok := arrayKinds[(int)(typeInfo.GetArrayType())]; ok {
+			return kind.ctype, "*"
+		}
 		ctype, p := CType(typeInfo.GetParamType(0))
 		return ctype, "*" + p
 	} else {
@@ -310,8 +620,8 @@ func CType(typeInfo *GiInfo) (string, string) {
 				interfaceType := typeInfo.GetTypeInterface()
 
 				if interfaceType.Type == Callback {
-					// TODO: enable callbacks
-					return "", ""
+					// callback typedefs are already function pointers
+					return GetPrefix(interfaceType) + interfaceType.GetName(), ""
 				} else {
 					return GetPrefix(interfaceType) + interfaceType.GetName(), ptr
 				}
@@ -0,0 +1,159 @@
+package gogi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// the names each type can't give its own methods, by namespace and name
+var reserved map[string]map[string]bool
+
+// the signatures of the methods written for each type, by namespace and name
+var methods map[string][]string
+
+// Gets the receiver type for the methods of a type
+func selfType(owner *GiInfo) string {
+	switch owner.Type {
+		case Object, Interface:
+			return "*" + GetImplName(owner.GetName())
+	}
+	return "*" + owner.GetName()
+}
+
+// Gets the Go name for one of a type's methods. Names the wrapper itself or
+// one of its ancestors already uses get the type's name in front, e.g.
+// TextView's GetWindow becomes TextViewGetWindow, since overriding them would
+// stop it from satisfying its ancestors' interfaces.
+func methodName(owner *GiInfo, name string) string {
+	if reservedNames(owner)[name] {
+		return owner.GetName() + name
+	}
+	return name
+}
+
+// Gets the Go name for a constructor: new becomes New<Type>, and the likes
+// of new_with_label become New<Type>WithLabel
+func constructorName(owner *GiInfo, name string) string {
+	if name == "new" {
+		return "New" + owner.GetName()
+	}
+	if strings.HasPrefix(name, "new_") {
+		return "New" + owner.GetName() + CamelCase(name[len("new_"):])
+	}
+	return owner.GetName() + CamelCase(name)
+}
+
+// Checks whether a function constructs an instance of an object type, as
+// opposed to a struct or something else it returns
+func constructs(info *GiInfo, owner *GiInfo) bool {
+	if owner == nil || owner.Type != Object || !info.GetFunctionFlags().IsConstructor {
+		return false
+	}
+	returnType := info.GetReturnType() ; defer returnType.Free()
+	if returnType.GetTag() != InterfaceTag {
+		return false
+	}
+	interfaceInfo := returnType.GetTypeInterface() ; defer interfaceInfo.Free()
+	return interfaceInfo.Type == Object || interfaceInfo.Type == Interface
+}
+
+func reservedNames(owner *GiInfo) map[string]bool {
+	key := owner.GetNamespace() + "." + owner.GetName()
+	if names, ok := reserved[key]; ok {
+		return names
+	}
+	names := make(map[string]bool)
+	names["Native"] = true
+	names["GType"] = true
+	if owner.Type == Object || owner.Type == Interface {
+		for _, name := range []string{"SetNative", "Unref", "Close", "As" + owner.GetName()} {
+			names[name] = true
+		}
+		// the ones for subclassing and implementing it, including its own vfuncs' methods
+		for _, name := range append([]string{"InstallOverrides"}, vfuncMemberNames(owner)...) {
+			names[name] = true
+		}
+	}
+	if owner.Type == Object {
+		for _, ancestor := range ancestors(owner) {
+			names["As" + ancestor.GetName()] = true
+			for _, name := range memberNames(ancestor) {
+				names[name] = true
+			}
+			ancestor.Free()
+		}
+	}
+	reserved[key] = names
+	return names
+}
+
+// Gets the names of the Go methods an object or interface type might have,
+// whether or not they can all be generated, leaving out the wrapper's own
+func memberNames(info *GiInfo) (names []string) {
+	method_count, getMethod := info.GetNObjectMethods(), info.GetObjectMethod
+	property_count, getProperty := info.GetNObjectProperties(), info.GetObjectProperty
+	signal_count, getSignal := info.GetNSignals(), info.GetObjectSignal
+	field_count := info.GetNObjectFields()
+	if info.Type == Interface {
+		method_count, getMethod = info.GetNInterfaceMethods(), info.GetInterfaceMethod
+		property_count, getProperty = info.GetNInterfaceProperties(), info.GetInterfaceProperty
+		signal_count, getSignal = info.GetNInterfaceSignals(), info.GetInterfaceSignal
+		field_count = 0
+	}
+	for i := 0; i < method_count; i++ {
+		method := getMethod(i) ; defer method.Free()
+		if method.GetFunctionFlags().IsMethod {
+			names = append(names, CamelCase(method.GetName()))
+			if hasOptionalOuts(method) {
+				names = append(names, CamelCase(method.GetName()) + "SkipOptional")
+			}
+		}
+	}
+	for i := 0; i < property_count; i++ {
+		property := getProperty(i) ; defer property.Free()
+		name := CamelCase(strings.Replace(property.GetName(), "-", "_", -1))
+		names = append(names, "Get" + name, "Set" + name, "Notify" + name)
+	}
+	for i := 0; i < signal_count; i++ {
+		signal := getSignal(i) ; defer signal.Free()
+		names = append(names, "Connect" + CamelCase(strings.Replace(signal.GetName(), "-", "_", -1)))
+	}
+	for i := 0; i < field_count; i++ {
+		field := info.GetObjectField(i) ; defer field.Free()
+		name := CamelCase(field.GetName())
+		names = append(names, "Get" + name, "Set" + name)
+	}
+	names = append(names, vfuncMemberNames(info)...)
+	return
+}
+
+// Gets an object type's ancestors, nearest first. Each needs freeing. The
+// walk ends at a fundamental type, like GObject or GParamSpec, which is the
+// root of its hierarchy whatever its namespace or name.
+func ancestors(info *GiInfo) (results []*GiInfo) {
+	iter := info
+	for !iter.IsFundamental() {
+		iter = iter.GetParent()
+		if iter == nil {
+			break
+		}
+		results = append(results, iter)
+	}
+	return
+}
+
+// Records the signature of one of a type's methods, as its name, parameters
+// and results, once it's been written, for listing it in the type's interface
+func addMethod(owner *GiInfo, signature string) {
+	key := owner.GetNamespace() + "." + owner.GetName()
+	methods[key] = append(methods[key], signature)
+}
+
+// Gets the signatures of the methods written for a type, for listing them in
+// its interface
+func methodSignatures(owner *GiInfo) (g string) {
+	for _, signature := range methods[owner.GetNamespace() + "." + owner.GetName()] {
+		g += fmt.Sprintf("\t%s\n", signature)
+	}
+	return
+}
@@ -0,0 +1,198 @@
+package gogi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// How a type is stored in a GValue: its GType, the suffix of its
+// g_value_get_*/g_value_set_* accessors and the C type those take
+type gvalueType struct {
+	gtype string
+	accessor string
+	ctype string
+}
+
+var gvalueTypes = map[int]gvalueType {
+	(int)(BooleanTag):  {"C.G_TYPE_BOOLEAN", "boolean", "C.gboolean"},
+	(int)(Int8Tag):     {"C.G_TYPE_CHAR", "schar", "C.gint8"},
+	(int)(Uint8Tag):    {"C.G_TYPE_UCHAR", "uchar", "C.guchar"},
+	(int)(Int32Tag):    {"C.G_TYPE_INT", "int", "C.gint"},
+	(int)(Uint32Tag):   {"C.G_TYPE_UINT", "uint", "C.guint"},
+	(int)(Int64Tag):    {"C.G_TYPE_INT64", "int64", "C.gint64"},
+	(int)(Uint64Tag):   {"C.G_TYPE_UINT64", "uint64", "C.guint64"},
+	(int)(FloatTag):    {"C.G_TYPE_FLOAT", "float", "C.gfloat"},
+	(int)(DoubleTag):   {"C.G_TYPE_DOUBLE", "double", "C.gdouble"},
+	(int)(GTypeTag):    {"C.g_gtype_get_type()", "gtype", "C.GType"},
+	(int)(Utf8Tag):     {"C.G_TYPE_STRING", "string", "*C.gchar"},
+	(int)(FilenameTag): {"C.G_TYPE_STRING", "string", "*C.gchar"},
+}
+
+// Gets how a type is stored in a GValue; ok is false if it can't be
+func getGValueType(typeInfo *GiInfo) (gvalueType, bool) {
+	tag := typeInfo.GetTag()
+	if tag != InterfaceTag {
+		t, ok := gvalueTypes[(int)(tag)]
+		return t, ok
+	}
+
+	interfaceInfo := typeInfo.GetTypeInterface() ; defer interfaceInfo.Free()
+	init := interfaceInfo.GetRegisteredTypeInit()
+	if init == "" || init == "intern" {
+		return gvalueType{}, false
+	}
+	gtype := "C." + init + "()"
+	switch interfaceInfo.Type {
+		case Enum:
+			return gvalueType{gtype, "enum", "C.gint"}, true
+		case Flags:
+			return gvalueType{gtype, "flags", "C.guint"}, true
+		case Object:
+			return gvalueType{gtype, "object", "C.gpointer"}, true
+		case Struct, Boxed:
+			return gvalueType{gtype, "boxed", "C.gpointer"}, true
+	}
+	return gvalueType{}, false
+}
+
+// Writes the accessors and notify functions for each of an object's or interface's properties
+func writeProperties(info *GiInfo) (g string, c string) {
+	property_count, getProperty := info.GetNObjectProperties(), info.GetObjectProperty
+	if info.Type == Interface {
+		property_count, getProperty = info.GetNInterfaceProperties(), info.GetInterfaceProperty
+	}
+	for i := 0; i < property_count; i++ {
+		property := getProperty(i) ; defer property.Free()
+		if property.IsDeprecated() {
+			continue
+		}
+		g_, c_ := WriteProperty(property, info)
+		if g_ != "" {
+			g += g_ + "\n"
+		}
+		c += c_
+	}
+	return
+}
+
+// Writes the methods Get<Prop>() for readable properties, Set<Prop>(value) for
+// writable ones that aren't construct-only, and Notify<Prop>(handler) to
+// subscribe to changes. Accessors that would clash with a method of the same
+// name are left out, since the method does the same job.
+func WriteProperty(info *GiInfo, owner *GiInfo) (g string, c string) {
+	ownerName := owner.GetName()
+	propertyName := info.GetName()
+	goName := CamelCase(strings.Replace(propertyName, "-", "_", -1))
+	flags := info.GetPropertyFlags()
+	typ := info.GetPropertyType() ; defer typ.Free()
+
+	gotype, gp := GoType(typ)
+	ctype, cp := CType(typ)
+	vt, ok := getGValueType(typ)
+	if !ok || gotype == "" || ctype == "" || blacklist[gotype] {
+		return
+	}
+	if vt.ctype == "C.gpointer" {
+		// objects and boxed types always come out of a GValue as pointers
+		cp = "*"
+	}
+	propertySupport(&g)
+
+	getter := ownerName + "Get" + goName
+	if flags.Readable && !goFuncs[getter] {
+		retType, marshal := MarshalToGo(Argument{info, typ, In, "retval", "c_retval", ""})
+		if retType != "" {
+			signature := fmt.Sprintf("%s() %s", methodName(owner, "Get" + goName), gp + gotype)
+			g += fmt.Sprintf("func (self %s) %s {\n", selfType(owner), signature)
+			g += "\tvar c_value C.GValue\n"
+			g += fmt.Sprintf("\tC.g_value_init(&c_value, %s)\n", vt.gtype)
+			g += "\tdefer C.g_value_unset(&c_value)\n"
+			g += fmt.Sprintf("\tpropertyGet((C.gpointer)(self.As%s()), \"%s\", &c_value)\n", ownerName, propertyName)
+			g += fmt.Sprintf("\tc_retval := (%sC.%s)(C.g_value_get_%s(&c_value))\n", cp, ctype, vt.accessor)
+			g += "\t" + marshal + "\n"
+			g += "\treturn retval\n"
+			g += "}\n"
+			goFuncs[getter] = true
+			addMethod(owner, signature)
+		}
+	}
+
+	setter := ownerName + "Set" + goName
+	if flags.Writable && !flags.ConstructOnly && !goFuncs[setter] {
+		argType, marshal := MarshalToC(Argument{info, typ, In, "value", "c_v", ""})
+		if argType != "" && argType != "C." {
+			signature := fmt.Sprintf("%s(value %s)", methodName(owner, "Set" + goName), gp + gotype)
+			g += fmt.Sprintf("func (self %s) %s {\n", selfType(owner), signature)
+			g += "\tvar c_value C.GValue\n"
+			g += fmt.Sprintf("\tC.g_value_init(&c_value, %s)\n", vt.gtype)
+			g += "\tdefer C.g_value_unset(&c_value)\n"
+			g += fmt.Sprintf("\tvar c_v %s\n", argType)
+			g += "\t" + marshal + "\n"
+			g += fmt.Sprintf("\tC.g_value_set_%s(&c_value, (%s)(c_v))\n", vt.accessor, vt.ctype)
+			g += fmt.Sprintf("\tpropertySet((C.gpointer)(self.As%s()), \"%s\", &c_value)\n", ownerName, propertyName)
+			g += "}\n"
+			goFuncs[setter] = true
+			addMethod(owner, signature)
+		}
+	}
+
+	notify := ownerName + "Notify" + goName
+	if !goFuncs[notify] {
+		g_, c_ := notifyTrampoline(owner)
+		g += g_
+		c += c_
+		signature := fmt.Sprintf("%s(handler func(self %s)) SignalHandler", methodName(owner, "Notify" + goName), ownerName)
+		g += fmt.Sprintf("func (self %s) %s {\n", selfType(owner), signature)
+		g += fmt.Sprintf("\treturn signalConnect((C.gpointer)(self.As%s()), \"notify::%s\", (C.GCallback)(C.gogi_notify_%s), handler)\n", ownerName, propertyName, GetPrefix(owner) + ownerName)
+		g += "}\n"
+		goFuncs[notify] = true
+		addMethod(owner, signature)
+	}
+	return
+}
+
+// Writes the "notify" signal handler for an object type, if it hasn't been yet
+func notifyTrampoline(owner *GiInfo) (g string, c string) {
+	ownerName := owner.GetName()
+	cname := GetPrefix(owner) + ownerName
+	name := "notify_" + cname
+	export := "gogi_" + name
+	if cExports[export] {
+		return
+	}
+	cExports[export] = true
+	signalSupport(&g, &c)
+
+	params := fmt.Sprintf("c_self *C.%s, c_pspec *C.GParamSpec, c_user_data C.gpointer", cname)
+	c += fmt.Sprintf("extern void %s(%s *self, GParamSpec *pspec, gpointer user_data);\n", export, cname)
+
+	goExports += fmt.Sprintf("//export %s\n", export)
+	goExports += fmt.Sprintf("func %s(%s) {\n", export, params)
+	goExports += fmt.Sprintf("\t%s(c_self, c_pspec, c_user_data)\n", name)
+	goExports += "}\n\n"
+
+	g += fmt.Sprintf("func %s(%s) {\n", name, params)
+	g += fmt.Sprintf("\tfn := callbackGet(c_user_data).(func(%s))\n", ownerName)
+	g += fmt.Sprintf("\tfn(wrap%s(c_self, false))\n", ownerName)
+	g += "}\n"
+	return
+}
+
+// Writes the helpers shared by property accessors, if they haven't been yet
+func propertySupport(g *string) {
+	if cExports["gogi_property"] {
+		return
+	}
+	cExports["gogi_property"] = true
+
+	(*g) += "func propertyGet(instance C.gpointer, name string, value *C.GValue) {\n"
+	(*g) += "\tc_name := (*C.gchar)(C.CString(name))\n"
+	(*g) += "\tdefer C.g_free((C.gpointer)(c_name))\n"
+	(*g) += "\tC.g_object_get_property((*C.GObject)(instance), c_name, value)\n"
+	(*g) += "}\n"
+	(*g) += "func propertySet(instance C.gpointer, name string, value *C.GValue) {\n"
+	(*g) += "\tc_name := (*C.gchar)(C.CString(name))\n"
+	(*g) += "\tdefer C.g_free((C.gpointer)(c_name))\n"
+	(*g) += "\tC.g_object_set_property((*C.GObject)(instance), c_name, value)\n"
+	(*g) += "}\n\n"
+}
@@ -0,0 +1,130 @@
+package gogi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Writes a Connect function for each of an object's or interface's signals
+func writeSignals(info *GiInfo) (g string, c string) {
+	signal_count, getSignal := info.GetNSignals(), info.GetObjectSignal
+	if info.Type == Interface {
+		signal_count, getSignal = info.GetNInterfaceSignals(), info.GetInterfaceSignal
+	}
+	for i := 0; i < signal_count; i++ {
+		signal := getSignal(i) ; defer signal.Free()
+		if signal.IsDeprecated() {
+			continue
+		}
+		g_, c_ := WriteSignal(signal, info)
+		if g_ != "" {
+			g += g_ + "\n"
+		}
+		c += c_
+	}
+	return
+}
+
+// Writes the method Connect<Signal>(handler), which returns a SignalHandler.
+// If the signal has a return value, such as the "stop" boolean of event
+// signals, the handler's return value is passed back to the emitter.
+func WriteSignal(info *GiInfo, owner *GiInfo) (g string, c string) {
+	ownerName := owner.GetName()
+	signalName := info.GetName()
+	cname := strings.Replace(signalName, "-", "_", -1)
+
+	fnType := signalSignature(info, owner)
+	if fnType == "" {
+		return
+	}
+	signalSupport(&g, &c)
+
+	name := fmt.Sprintf("signal_%s_%s", GetPrefix(owner) + ownerName, cname)
+	g_, c_ := writeTrampoline(name, info, owner, -1, fnType)
+	g += g_
+	c += c_
+
+	signature := fmt.Sprintf("%s(handler %s) SignalHandler", methodName(owner, "Connect" + CamelCase(cname)), fnType)
+	g += fmt.Sprintf("func (self %s) %s {\n", selfType(owner), signature)
+	g += fmt.Sprintf("\treturn signalConnect((C.gpointer)(self.As%s()), \"%s\", (C.GCallback)(C.gogi_%s), handler)\n", ownerName, signalName, name)
+	g += "}\n"
+	addMethod(owner, signature)
+	return
+}
+
+// Gets the Go func type for a signal handler, or "" if it can't be marshaled.
+// Its first parameter is the owner's instance, unless owner is nil.
+func signalSignature(info *GiInfo, owner *GiInfo) string {
+	params := make([]string, 0)
+	if owner != nil {
+		params = append(params, "self " + owner.GetName())
+	}
+	for i := 0; i < info.GetNArgs(); i++ {
+		arg := info.GetArg(i) ; defer arg.Free()
+		typ := arg.GetType() ; defer typ.Free()
+		if arg.GetDirection() != In || isCallback(typ) {
+			return ""
+		}
+		gotype, gp := GoType(typ)
+		ctype, _ := CType(typ)
+		if gotype == "" || ctype == "" || blacklist[gotype] {
+			return ""
+		}
+		params = append(params, fmt.Sprintf("%s %s", noKeywords(arg.GetName()), gp + gotype))
+	}
+
+	signature := "func(" + strings.Join(params, ", ") + ")"
+
+	returnType := info.GetReturnType() ; defer returnType.Free()
+	if returnType.GetTag() != VoidTag || returnType.IsPointer() {
+		gotype, gp := GoType(returnType)
+		if gotype == "" || blacklist[gotype] {
+			return ""
+		}
+		signature += " " + gp + gotype
+	}
+	return signature
+}
+
+// Writes the SignalHandler type and the connect helpers, if they haven't been yet
+func signalSupport(g *string, c *string) {
+	if cExports["gogi_signal_connect"] {
+		return
+	}
+	cExports["gogi_signal_connect"] = true
+
+	destroy := exportName("signal_destroy")
+	(*c) += fmt.Sprintf("extern void %s(gpointer data, GClosure *closure);\n", destroy)
+	(*c) += "static gulong gogi_signal_connect(gpointer instance, const gchar *signal, GCallback handler, gpointer data) {\n"
+	(*c) += fmt.Sprintf("\treturn g_signal_connect_data(instance, signal, handler, data, %s, 0);\n", destroy)
+	(*c) += "}\n"
+
+	goExports += fmt.Sprintf("//export %s\n", destroy)
+	goExports += fmt.Sprintf("func %s(data C.gpointer, closure *C.GClosure) {\n", destroy)
+	goExports += "\tcallbackRemove(data)\n"
+	goExports += "}\n\n"
+
+	(*g) += "// A connected signal handler; disconnecting it releases the Go func\n"
+	(*g) += "type SignalHandler struct {\n"
+	(*g) += "\tinstance C.gpointer\n"
+	(*g) += "\tid C.gulong\n"
+	(*g) += "}\n"
+	(*g) += "func (h SignalHandler) Disconnect() {\n"
+	(*g) += "\tC.g_signal_handler_disconnect(h.instance, h.id)\n"
+	(*g) += "}\n"
+	(*g) += "func (h SignalHandler) Block() {\n"
+	(*g) += "\tC.g_signal_handler_block(h.instance, h.id)\n"
+	(*g) += "}\n"
+	(*g) += "func (h SignalHandler) Unblock() {\n"
+	(*g) += "\tC.g_signal_handler_unblock(h.instance, h.id)\n"
+	(*g) += "}\n"
+	(*g) += "func (h SignalHandler) IsConnected() bool {\n"
+	(*g) += "\treturn C.g_signal_handler_is_connected(h.instance, h.id) != 0\n"
+	(*g) += "}\n"
+	(*g) += "func signalConnect(instance C.gpointer, signal string, handler C.GCallback, fn interface{}) SignalHandler {\n"
+	(*g) += "\tc_signal := (*C.gchar)(C.CString(signal))\n"
+	(*g) += "\tdefer C.g_free((C.gpointer)(c_signal))\n"
+	(*g) += "\tid := C.gogi_signal_connect(instance, c_signal, handler, callbackAdd(fn, false))\n"
+	(*g) += "\treturn SignalHandler{instance, id}\n"
+	(*g) += "}\n\n"
+}
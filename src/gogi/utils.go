@@ -13,8 +13,21 @@ func CamelCase(str string) (name string) {
 }
 
 // convert an interface name to its implementation name,
-// e.g. Window -> windowImpl
-// it's made lower-case in order to hide it
+// e.g. Window -> WindowImpl
+// it's exported so that subclasses, including those in other packages, can embed it
 func GetImplName(name string) string {
-	return strings.ToLower(name[0:1]) + name[1:] + "Impl"
+	return name + "Impl"
+}
+
+// Gets the name of the unexported type an interface's implementation embeds,
+// which has the methods the implementations of object types have their own
+// of, e.g. ListModel -> listModelBase. Go types that embed both an object
+// type's implementation and an interface's get those from the object type's.
+func interfaceBaseName(name string) string {
+	return strings.ToLower(name[:1]) + name[1:] + "Base"
+}
+
+// indents generated code that continues onto more lines by one more level
+func indent(code string) string {
+	return strings.Replace(code, "\n", "\n\t", -1)
 }
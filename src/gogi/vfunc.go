@@ -0,0 +1,255 @@
+package gogi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Gets the names of the methods for a vfunc: the one a Go subclass implements
+// to override it, and the one it calls to chain up
+func vfuncNames(info *GiInfo) (override string, chainUp string) {
+	name := CamelCase(info.GetName())
+	return "Do" + name, "Parent" + name
+}
+
+// Gets the names of the vfunc methods of an object or interface type, whether
+// or not they can all be generated. Interfaces have nothing to chain up to.
+func vfuncMemberNames(info *GiInfo) (names []string) {
+	vfunc_count, getVFunc := info.GetNVFuncs(), info.GetVFunc
+	if info.Type == Interface {
+		vfunc_count, getVFunc = info.GetNInterfaceVFuncs(), info.GetInterfaceVFunc
+	}
+	for i := 0; i < vfunc_count; i++ {
+		vfunc := getVFunc(i) ; defer vfunc.Free()
+		override, chainUp := vfuncNames(vfunc)
+		names = append(names, override)
+		if info.Type == Object {
+			names = append(names, chainUp)
+		}
+	}
+	return
+}
+
+// Gets the parameters and results of the method that overrides a vfunc, or ""
+// if it can't be overridden from Go
+func vfuncSignature(info *GiInfo) string {
+	if info.GetVFuncFlags().Throws || info.GetOffset() == 0xFFFF {
+		// TODO: vfuncs that throw
+		return ""
+	}
+	return strings.TrimPrefix(signalSignature(info, nil), "func")
+}
+
+// Gets the C function that returns an object or interface type's GType,
+// writing one if the typelib doesn't name it
+func gtypeFunc(info *GiInfo, c *string) string {
+	if init := info.GetRegisteredTypeInit(); init != "intern" {
+		return init
+	}
+	name := "gogi_" + strings.ToLower(GetPrefix(info) + info.GetName()) + "_get_type"
+	if !cExports[name] {
+		cExports[name] = true
+		(*c) += fmt.Sprintf("GType %s(void) {\n", name)
+		(*c) += fmt.Sprintf("\treturn g_type_from_name(\"%s\");\n", info.GetRegisteredTypeName())
+		(*c) += "}\n"
+	}
+	return name
+}
+
+// Writes what lets Go subclasses, registered with GObject's RegisterSubclass,
+// override an object's vfuncs. Each one gets a trampoline that calls the
+// Do<VFunc> method of the instance's Go value and a Parent<VFunc> method that
+// chains up to the implementation it replaced. InstallOverrides puts the
+// trampolines for the Do<VFunc> methods a Go value has into a class struct,
+// after its parent's do the same for theirs.
+//
+// Interfaces get the same, minus Parent<VFunc>, so that Go types can
+// implement them with GObject's AddInterface; their InstallOverrides fills in
+// an interface vtable instead.
+func writeVFuncs(info *GiInfo, parent *GiInfo, c *string) (g string) {
+	implName := GetImplName(info.GetName())
+
+	var install string
+	vfunc_count, getVFunc := info.GetNVFuncs(), info.GetVFunc
+	param := "class"
+	if info.Type == Interface {
+		vfunc_count, getVFunc = info.GetNInterfaceVFuncs(), info.GetInterfaceVFunc
+		param = "vtable"
+		implName = interfaceBaseName(info.GetName())
+	}
+	for i := 0; i < vfunc_count; i++ {
+		vfunc := getVFunc(i) ; defer vfunc.Free()
+		g_, c_ := writeVFunc(vfunc, info)
+		if g_ == "" {
+			continue
+		}
+		g += g_
+		(*c) += c_
+
+		override, _ := vfuncNames(vfunc)
+		install += fmt.Sprintf("\tif _, ok := override.(interface{ %s%s }); ok {\n", override, vfuncSignature(vfunc))
+		install += fmt.Sprintf("\t\tC.gogi_set_vfunc((C.gpointer)(%s), %d, (C.GCallback)(C.gogi_%s))\n", param, vfunc.GetOffset(), vfuncTrampolineName(vfunc, info))
+		install += "\t}\n"
+	}
+
+	g += fmt.Sprintf("func (ob *%s) InstallOverrides(%s unsafe.Pointer, override interface{}) {\n", implName, param)
+	if parent != nil {
+		g += fmt.Sprintf("\tob.%s.InstallOverrides(%s, override)\n", GetImplName(parent.GetName()), param)
+	}
+	g += install
+	g += "}\n"
+	return
+}
+
+func vfuncTrampolineName(info *GiInfo, owner *GiInfo) string {
+	return "vfunc_" + GetPrefix(owner) + owner.GetName() + "_" + info.GetName()
+}
+
+// Writes the trampoline for a vfunc and the Parent<VFunc> method, or nothing if
+// the vfunc can't be overridden. Parent<VFunc> calls the implementation in the
+// class struct of the parent of the topmost Go type in the instance's
+// hierarchy, since Go types chain up to each other by calling their embedded
+// type's Do<VFunc> instead. Overrides of vfuncs that must chain up do so after
+// they return if they didn't already. Interfaces' vfuncs only get the trampoline.
+func writeVFunc(info *GiInfo, owner *GiInfo) (g string, c string) {
+	signature := vfuncSignature(info)
+	if signature == "" {
+		return
+	}
+	ownerName := owner.GetName()
+	ownerCType := GetPrefix(owner) + ownerName
+	vfuncName := info.GetName()
+	name := vfuncTrampolineName(info, owner)
+	export := "gogi_" + name
+	chain := "gogi_chain_" + ownerCType + "_" + vfuncName
+	if cExports[export] {
+		return
+	}
+	override, chainUp := vfuncNames(info)
+	// interfaces are implemented from scratch, so there's nothing to chain up to
+	chains := owner.Type == Object
+	mustChainUp := chains && info.GetVFuncFlags().MustChainUp
+
+	returnType := info.GetReturnType() ; defer returnType.Free()
+	returns := returnType.GetTag() != VoidTag || returnType.IsPointer()
+	cret, gret := "void ", ""
+	if returns {
+		ctype, cp := CType(returnType)
+		cret = ctype + " " + cp
+		gret = " " + cp + "C." + ctype
+	}
+
+	// Parent<VFunc>, which marshals its arguments like any other method
+	var chainMarshal string
+	chainArgs := []string{fmt.Sprintf("(C.gpointer)(%s(self.Native()))", runtimeFunc("GObject", "SubclassParentClass")), fmt.Sprintf("self.As%s()", ownerName)}
+	cNames := []string{"self"}
+	for i := 0; i < info.GetNArgs(); i++ {
+		arg := info.GetArg(i) ; defer arg.Free()
+		typ := arg.GetType() ; defer typ.Free()
+		argName := arg.GetName()
+		ctype, marshal := MarshalToC(Argument{arg, typ, In, argName, "c_" + argName, ""})
+		if ctype == "" || ctype == "C." {
+			return
+		}
+		chainMarshal += fmt.Sprintf("\tvar c_%s %s\n", argName, ctype)
+		chainMarshal += fmt.Sprintf("\t%s\n", marshal)
+		chainArgs = append(chainArgs, "c_" + argName)
+		cNames = append(cNames, argName)
+	}
+	var retMarshal string
+	if returns {
+		var gotype string
+		gotype, retMarshal = MarshalToGo(Argument{info, returnType, In, "retval", "c_retval", ""})
+		if gotype == "" {
+			return
+		}
+	}
+	cExports[export] = true
+
+	cParams, gParams, cArgs, gArgs, marshal, _ := trampolineParams(info, -1)
+	cParamLine := append([]string{ownerCType + " *self"}, cParams...)
+	gParamLine := append([]string{"c_self *C." + ownerCType}, gParams...)
+	cArgLine := append([]string{"c_self"}, cArgs...)
+	params := strings.Join(gParamLine, ", ")
+
+	c += fmt.Sprintf("extern %s%s(%s);\n", cret, export, strings.Join(cParamLine, ", "))
+	if chains {
+		c += fmt.Sprintf("%s%s(gpointer klass, %s) {\n", cret, chain, strings.Join(cParamLine, ", "))
+		c += fmt.Sprintf("\t%s(*fn)(%s) = G_STRUCT_MEMBER(gpointer, klass, %d);\n", cret, strings.Join(cParamLine, ", "), info.GetOffset())
+		if returns {
+			c += "\tif (fn == NULL) {\n"
+			c += fmt.Sprintf("\t\t%szero = {0};\n", cret)
+			c += "\t\treturn zero;\n"
+			c += "\t}\n"
+			c += fmt.Sprintf("\treturn fn(%s);\n", strings.Join(cNames, ", "))
+		} else {
+			c += "\tif (fn != NULL) {\n"
+			c += fmt.Sprintf("\t\tfn(%s);\n", strings.Join(cNames, ", "))
+			c += "\t}\n"
+		}
+		c += "}\n"
+	}
+
+	goExports += fmt.Sprintf("//export %s\n", export)
+	goExports += fmt.Sprintf("func %s(%s)%s {\n", export, params, gret)
+	if returns {
+		goExports += fmt.Sprintf("\treturn %s(%s)\n", name, strings.Join(cArgLine, ", "))
+	} else {
+		goExports += fmt.Sprintf("\t%s(%s)\n", name, strings.Join(cArgLine, ", "))
+	}
+	goExports += "}\n\n"
+
+	g += fmt.Sprintf("func %s(%s)%s {\n", name, params, gret)
+	g += fmt.Sprintf("\tfn := %s(unsafe.Pointer(c_self)).(interface{ %s%s }).%s\n", runtimeFunc("GObject", "SubclassInstance"), override, signature, override)
+	g += marshal
+	if mustChainUp {
+		g += fmt.Sprintf("\tchainUpBegin((C.gpointer)(c_self), \"%s\")\n", vfuncName)
+	}
+	g += "\t"
+	if returns {
+		g += "retval := "
+	}
+	g += fmt.Sprintf("fn(%s)\n", strings.Join(gArgs, ", "))
+	if mustChainUp {
+		classArg := fmt.Sprintf("(C.gpointer)(%s(unsafe.Pointer(c_self)))", runtimeFunc("GObject", "SubclassParentClass"))
+		g += fmt.Sprintf("\tif !chainUpDone((C.gpointer)(c_self), \"%s\") {\n", vfuncName)
+		g += fmt.Sprintf("\t\tC.%s(%s)\n", chain, strings.Join(append([]string{classArg}, cArgLine...), ", "))
+		g += "\t}\n"
+	}
+	if returns {
+		// it outlives the call, so nothing it's marshaled into is freed when it returns
+		retArg := Argument{info, returnType, Out, "retval", "c_retval", ""}
+		ctype, m := MarshalToC(retArg)
+		g += fmt.Sprintf("\tvar c_retval %s\n", ctype)
+		g += fmt.Sprintf("\t%s\n", m)
+		switch returnType.GetTag() {
+			case Utf8Tag, FilenameTag:
+				if getTransfer(retArg) == Nothing {
+					// C doesn't free it, so the instance does once the next call's replaces it
+					g += fmt.Sprintf("\tkeepString((C.gpointer)(c_self), \"gogi-vfunc-%s\", (*C.gchar)(c_retval))\n", vfuncName)
+				}
+		}
+		g += "\treturn c_retval\n"
+	}
+	g += "}\n"
+	if !chains {
+		return
+	}
+
+	g += fmt.Sprintf("func (self *%s) %s%s {\n", GetImplName(ownerName), chainUp, signature)
+	g += chainMarshal
+	if mustChainUp {
+		g += fmt.Sprintf("\tchainUpMark((C.gpointer)(self.Native()), \"%s\")\n", vfuncName)
+	}
+	g += "\t"
+	if returns {
+		g += "c_retval, _ := "
+	}
+	g += fmt.Sprintf("C.%s(%s)\n", chain, strings.Join(chainArgs, ", "))
+	if returns {
+		g += fmt.Sprintf("\t%s\n", retMarshal)
+		g += "\treturn retval\n"
+	}
+	g += "}\n"
+	return
+}